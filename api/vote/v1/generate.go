@@ -0,0 +1,3 @@
+package votev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative vote/v1/vote.proto vote/v1/vote_ingest.proto
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/container"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/replay"
+)
+
+func main() {
+	cfg := config.Load()
+	cfg.Consumer.Source = "file"
+
+	flag.StringVar(&cfg.Consumer.ReplayFile, "file", cfg.Consumer.ReplayFile, "NDJSON or CSV file to replay, optionally gzipped")
+	flag.StringVar(&cfg.Consumer.ReplayFormat, "format", cfg.Consumer.ReplayFormat, "file format: auto, ndjson or csv")
+	flag.StringVar(&cfg.Consumer.ReplayCheckpoint, "checkpoint", cfg.Consumer.ReplayCheckpoint, "checkpoint file, defaults to <file>.checkpoint")
+	flag.Int64Var(&cfg.Consumer.ReplayOffset, "offset", cfg.Consumer.ReplayOffset, "byte offset to start from, overrides the checkpoint when >= 0")
+	flag.DurationVar(&cfg.Consumer.ReplayProgressInterval, "progress", cfg.Consumer.ReplayProgressInterval, "progress report interval")
+	flag.BoolVar(&cfg.Consumer.ReplayDryRun, "dry-run", cfg.Consumer.ReplayDryRun, "decode and validate only, without saving votes")
+	flag.Parse()
+
+	if cfg.Consumer.ReplayFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if cfg.Consumer.ReplayDryRun {
+		if err := dryRun(ctx, cfg); err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		return
+	}
+
+	consumer, err := newReplayConsumer(cfg)
+	if err != nil {
+		log.Fatalf("Invalid replay options: %v", err)
+	}
+
+	app := container.NewContainer(cfg, container.WithVoteConsumer(consumer))
+
+	if err := app.Build(); err != nil {
+		log.Fatalf("Failed to build application: %v", err)
+	}
+
+	if err := app.Start(ctx); err != nil {
+		log.Fatalf("Failed to start application: %v", err)
+	}
+
+	// the dispatcher returns once the file is exhausted or ctx is cancelled
+	app.Stop()
+	app.Close()
+
+	if failed := consumer.Failed(); failed > 0 {
+		log.Printf("Replay finished with %d failed votes, rerun to replay them from the checkpoint", failed)
+		os.Exit(1)
+	}
+
+	log.Println("Replay finished")
+}
+
+// newReplayConsumer builds the file consumer here instead of leaving it to
+// the container, so its failure count can be read once the replay is done.
+func newReplayConsumer(cfg *config.Config) (*replay.FileVoteConsumer, error) {
+	format, err := replay.ParseFormat(cfg.Consumer.ReplayFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer := replay.NewFileVoteConsumer(replay.Options{
+		Path:             cfg.Consumer.ReplayFile,
+		Format:           format,
+		CheckpointPath:   cfg.Consumer.ReplayCheckpoint,
+		StartOffset:      cfg.Consumer.ReplayOffset,
+		ProgressInterval: cfg.Consumer.ReplayProgressInterval,
+		Buffer:           cfg.Kafka.BatchSize,
+		DryRun:           cfg.Consumer.ReplayDryRun,
+	}, messaging.NewDecoderRegistry())
+
+	return consumer.(*replay.FileVoteConsumer), nil
+}
+
+func dryRun(ctx context.Context, cfg *config.Config) error {
+	consumer, err := newReplayConsumer(cfg)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := consumer.Consume(ctx)
+	if err != nil {
+		return err
+	}
+
+	for delivery := range deliveries {
+		if err := delivery.Vote().Validate(); err != nil {
+			delivery.Reject(err)
+			continue
+		}
+		delivery.Ack()
+	}
+
+	log.Println("Dry run finished")
+	return consumer.Close()
+}
@@ -11,14 +11,19 @@ import (
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Kafka    KafkaConfig
+	App            AppConfig
+	Database       DatabaseConfig
+	Consumer       ConsumerConfig
+	Kafka          KafkaConfig
+	SchemaRegistry SchemaRegistryConfig
+	Redis          RedisConfig
+	Outbox         OutboxConfig
 }
 
 type AppConfig struct {
 	Environment string
 	LogLevel    string
+	IDStrategy  string
 }
 
 type DatabaseConfig struct {
@@ -33,14 +38,80 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 	RunMigrations   bool
 	MigrationsPath  string
+	BulkMode        string
+	BulkChunkSize   int
+	BulkChunkTx     bool
+	InsertOnly      bool
+}
+
+type ConsumerConfig struct {
+	Source   string
+	HTTPAddr string
+	GRPCAddr string
+
+	GRPCMaxStreamVotes int
+
+	ReplayFile             string
+	ReplayFormat           string
+	ReplayCheckpoint       string
+	ReplayOffset           int64
+	ReplayProgressInterval time.Duration
+	ReplayDryRun           bool
+
+	InboxPollInterval  time.Duration
+	InboxLeaseDuration time.Duration
+	InboxMaxAttempts   int
 }
 
 type KafkaConfig struct {
-	Brokers       []string
-	Topic         string
-	ConsumerGroup string
-	BatchSize     int
-	Workers       int
+	Brokers         []string
+	Topic           string
+	ConsumerGroup   string
+	BatchSize       int
+	BatchLinger     time.Duration
+	Workers         int
+	CommitInterval  time.Duration
+	DeadLetterTopic string
+	ResultTopic     string
+
+	// reply-to topics must start with one of these prefixes
+	ReplyTopicPrefixes []string
+
+	DefaultContentType string
+
+	RetryTiers       int
+	RetryBackoff     time.Duration
+	RetryMultiplier  int
+	RetryMaxAttempts int
+}
+
+type SchemaRegistryConfig struct {
+	URL      string
+	Username string
+	Password string
+	Dir      string
+	Timeout  time.Duration
+}
+
+type RedisConfig struct {
+	Addr              string
+	Password          string
+	DB                int
+	Stream            string
+	ConsumerGroup     string
+	ConsumerName      string
+	DeadLetterStream  string
+	BlockTimeout      time.Duration
+	VisibilityTimeout time.Duration
+	MaxDeliveries     int
+}
+
+type OutboxConfig struct {
+	Enabled         bool
+	PollInterval    time.Duration
+	BatchSize       int
+	Retention       time.Duration
+	CleanupInterval time.Duration
 }
 
 func Load() *Config {
@@ -53,6 +124,7 @@ func Load() *Config {
 		App: AppConfig{
 			Environment: getEnv("ENVIRONMENT", "development"),
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
+			IDStrategy:  getEnv("ID_STRATEGY", "uuidv7"),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -66,13 +138,74 @@ func Load() *Config {
 			ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", "5m"),
 			RunMigrations:   getEnvBool("DB_RUN_MIGRATIONS", true),
 			MigrationsPath:  getEnv("DB_MIGRATIONS_PATH", "./internal/infrastructure/persistence/migrations"),
+			BulkMode:        getEnv("DB_BULK_MODE", "insert"),
+			BulkChunkSize:   getEnvInt("DB_BULK_CHUNK_SIZE", 5000),
+			BulkChunkTx:     getEnvBool("DB_BULK_CHUNK_TX", false),
+			InsertOnly:      getEnvBool("DB_INSERT_ONLY", false),
+		},
+		Consumer: ConsumerConfig{
+			Source:   getEnv("VOTE_SOURCE", "kafka"),
+			HTTPAddr: getEnv("HTTP_ADDR", ":8080"),
+			GRPCAddr: getEnv("GRPC_ADDR", ":9090"),
+
+			GRPCMaxStreamVotes: getEnvInt("GRPC_MAX_STREAM_VOTES", 10000),
+
+			ReplayFile:             getEnv("REPLAY_FILE", ""),
+			ReplayFormat:           getEnv("REPLAY_FORMAT", "auto"),
+			ReplayCheckpoint:       getEnv("REPLAY_CHECKPOINT", ""),
+			ReplayOffset:           int64(getEnvInt("REPLAY_OFFSET", -1)),
+			ReplayProgressInterval: getEnvDuration("REPLAY_PROGRESS_INTERVAL", "5s"),
+			ReplayDryRun:           getEnvBool("REPLAY_DRY_RUN", false),
+
+			InboxPollInterval:  getEnvDuration("INBOX_POLL_INTERVAL", "500ms"),
+			InboxLeaseDuration: getEnvDuration("INBOX_LEASE_DURATION", "30s"),
+			InboxMaxAttempts:   getEnvInt("INBOX_MAX_ATTEMPTS", 5),
 		},
 		Kafka: KafkaConfig{
-			Brokers:       getEnvSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
-			Topic:         getEnv("KAFKA_TOPIC", "votos"),
-			ConsumerGroup: getEnv("KAFKA_CONSUMER_GROUP", "vote-processor"),
-			BatchSize:     getEnvInt("KAFKA_BATCH_SIZE", 1000),
-			Workers:       getEnvInt("KAFKA_WORKERS", 5),
+			Brokers:         getEnvSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
+			Topic:           getEnv("KAFKA_TOPIC", "votos"),
+			ConsumerGroup:   getEnv("KAFKA_CONSUMER_GROUP", "vote-processor"),
+			BatchSize:       getEnvInt("KAFKA_BATCH_SIZE", 1000),
+			BatchLinger:     getEnvDuration("KAFKA_BATCH_LINGER", "1s"),
+			Workers:         getEnvInt("KAFKA_WORKERS", 5),
+			CommitInterval:  getEnvDuration("KAFKA_COMMIT_INTERVAL", "1s"),
+			DeadLetterTopic: getEnv("KAFKA_DLQ_TOPIC", "votos.dlq"),
+			ResultTopic:     getEnv("KAFKA_RESULT_TOPIC", ""),
+
+			ReplyTopicPrefixes: getEnvSlice("KAFKA_REPLY_TOPIC_PREFIXES", []string{"votos.reply."}),
+
+			DefaultContentType: getEnv("KAFKA_DEFAULT_CONTENT_TYPE", "application/json"),
+
+			RetryTiers:       getEnvInt("KAFKA_RETRY_TIERS", 2),
+			RetryBackoff:     getEnvDuration("KAFKA_RETRY_BACKOFF", "1m"),
+			RetryMultiplier:  getEnvInt("KAFKA_RETRY_BACKOFF_MULTIPLIER", 10),
+			RetryMaxAttempts: getEnvInt("KAFKA_RETRY_MAX_ATTEMPTS", 3),
+		},
+		SchemaRegistry: SchemaRegistryConfig{
+			URL:      getEnv("SCHEMA_REGISTRY_URL", ""),
+			Username: getEnv("SCHEMA_REGISTRY_USERNAME", ""),
+			Password: getEnv("SCHEMA_REGISTRY_PASSWORD", ""),
+			Dir:      getEnv("SCHEMA_REGISTRY_DIR", ""),
+			Timeout:  getEnvDuration("SCHEMA_REGISTRY_TIMEOUT", "5s"),
+		},
+		Redis: RedisConfig{
+			Addr:              getEnv("REDIS_ADDR", "localhost:6379"),
+			Password:          getEnv("REDIS_PASSWORD", ""),
+			DB:                getEnvInt("REDIS_DB", 0),
+			Stream:            getEnv("REDIS_STREAM", "votos"),
+			ConsumerGroup:     getEnv("REDIS_CONSUMER_GROUP", "vote-processor"),
+			ConsumerName:      getEnv("REDIS_CONSUMER_NAME", hostname()),
+			DeadLetterStream:  getEnv("REDIS_DLQ_STREAM", "votos.dlq"),
+			BlockTimeout:      getEnvDuration("REDIS_BLOCK_TIMEOUT", "1s"),
+			VisibilityTimeout: getEnvDuration("REDIS_VISIBILITY_TIMEOUT", "30s"),
+			MaxDeliveries:     getEnvInt("REDIS_MAX_DELIVERIES", 5),
+		},
+		Outbox: OutboxConfig{
+			Enabled:         getEnvBool("OUTBOX_ENABLED", false),
+			PollInterval:    getEnvDuration("OUTBOX_POLL_INTERVAL", "500ms"),
+			BatchSize:       getEnvInt("OUTBOX_BATCH_SIZE", 500),
+			Retention:       getEnvDuration("OUTBOX_RETENTION", "24h"),
+			CleanupInterval: getEnvDuration("OUTBOX_CLEANUP_INTERVAL", "10m"),
 		},
 	}
 }
@@ -116,3 +249,10 @@ func getEnvSlice(key string, defaultValue []string) []string {
 	}
 	return defaultValue
 }
+
+func hostname() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "vote-processor"
+}
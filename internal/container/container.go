@@ -4,11 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/pdrhp/ms-voto-processor-go/internal/config"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/usecase"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/util"
+	"github.com/pdrhp/ms-voto-processor-go/internal/dispatcher"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/grpcserver"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/httpserver"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
 	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/replay"
+	"github.com/redis/go-redis/v9"
 )
 
 type Container struct {
@@ -16,19 +25,52 @@ type Container struct {
 
 	database     *persistence.Database
 	migrator     *persistence.Migrator
+	outboxRelay  *persistence.OutboxRelay
 
-	voteRepository port.VoteRepositoryPort
+	voteRepository  port.VoteRepositoryPort
+	voteConsumer    port.VoteConsumerPort
+	resultPublisher port.VoteResultPublisherPort
 
 	voteProcessor *usecase.VoteProcessorUsecase
 
+	dispatcher *dispatcher.Dispatcher
+
 	isBuilt   bool
 	isHealthy bool
 }
 
-func NewContainer(cfg *config.Config) *Container {
-	return &Container{
+// Option replaces a component the container would otherwise build, so the
+// pipeline can run against fakes without Postgres or a broker.
+type Option func(*Container)
+
+func WithVoteRepository(repository port.VoteRepositoryPort) Option {
+	return func(c *Container) {
+		c.voteRepository = repository
+	}
+}
+
+func WithVoteConsumer(consumer port.VoteConsumerPort) Option {
+	return func(c *Container) {
+		c.voteConsumer = consumer
+	}
+}
+
+func WithResultPublisher(publisher port.VoteResultPublisherPort) Option {
+	return func(c *Container) {
+		c.resultPublisher = publisher
+	}
+}
+
+func NewContainer(cfg *config.Config, opts ...Option) *Container {
+	c := &Container{
 		config: cfg,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 func (c *Container) Build() error {
@@ -36,25 +78,46 @@ func (c *Container) Build() error {
 		return fmt.Errorf("container already built")
 	}
 
-
-	if err := c.buildDatabase(); err != nil {
-		return fmt.Errorf("failed to build database: %w", err)
+	if c.config.App.IDStrategy != "" {
+		strategy, err := util.ParseIDStrategy(c.config.App.IDStrategy)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		util.SetIDStrategy(strategy)
 	}
 
-	log.Println("Database connection established")
+	if c.needsDatabase() {
+		if err := c.buildDatabase(); err != nil {
+			return fmt.Errorf("failed to build database: %w", err)
+		}
+
+		log.Println("Database connection established")
 
-	if err := c.runMigrations(); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		if err := c.runMigrations(); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
 	}
 
 	if err := c.buildRepositories(); err != nil {
 		return fmt.Errorf("failed to build repositories: %w", err)
 	}
 
+	if err := c.buildConsumers(); err != nil {
+		return fmt.Errorf("failed to build consumers: %w", err)
+	}
+
+	if err := c.buildPublishers(); err != nil {
+		return fmt.Errorf("failed to build publishers: %w", err)
+	}
+
 	if err := c.buildUseCases(); err != nil {
 		return fmt.Errorf("failed to build use cases: %w", err)
 	}
 
+	if err := c.buildDispatcher(); err != nil {
+		return fmt.Errorf("failed to build dispatcher: %w", err)
+	}
+
 	if err := c.performHealthCheck(); err != nil {
 		return fmt.Errorf("failed to perform health check: %w", err)
 	}
@@ -65,6 +128,10 @@ func (c *Container) Build() error {
 	return nil
 }
 
+func (c *Container) needsDatabase() bool {
+	return c.voteRepository == nil || (c.voteConsumer == nil && c.config.Consumer.Source == "postgres")
+}
+
 func (c *Container) buildDatabase() error {
 	db, err := persistence.NewConnection(&c.config.Database)
 	if err != nil {
@@ -93,7 +160,147 @@ func (c *Container) runMigrations() error {
 }
 
 func (c *Container) buildRepositories() error {
-	c.voteRepository = persistence.NewPostgresVoteRepository(c.database)
+	if c.voteRepository != nil {
+		return nil
+	}
+
+	switch c.config.Database.BulkMode {
+	case persistence.BulkModeInsert, persistence.BulkModeCopy:
+	default:
+		return fmt.Errorf("unknown bulk mode: %s", c.config.Database.BulkMode)
+	}
+
+	c.voteRepository = persistence.NewPostgresVoteRepository(c.database, persistence.RepositoryOptions{
+		Outbox:            c.config.Outbox.Enabled,
+		BulkMode:          c.config.Database.BulkMode,
+		ChunkSize:         c.config.Database.BulkChunkSize,
+		ChunkTransactions: c.config.Database.BulkChunkTx,
+		InsertOnly:        c.config.Database.InsertOnly,
+	})
+
+	return nil
+}
+
+func (c *Container) buildConsumers() error {
+	if c.voteConsumer != nil {
+		return nil
+	}
+
+	switch c.config.Consumer.Source {
+	case "kafka":
+		c.voteConsumer = c.buildKafkaConsumer()
+	case "http":
+		c.voteConsumer = httpserver.NewHTTPVoteConsumer(c.config.Consumer.HTTPAddr, c.config.Kafka.BatchSize)
+	case "grpc":
+		c.voteConsumer = grpcserver.NewGRPCVoteConsumer(c.config.Consumer.GRPCAddr, c.config.Kafka.BatchSize, c.config.Consumer.GRPCMaxStreamVotes)
+	case "redis":
+		c.voteConsumer = c.buildRedisConsumer()
+	case "postgres":
+		c.voteConsumer = persistence.NewPostgresInboxConsumer(c.database, &c.config.Consumer, c.config.Kafka.BatchSize)
+	case "file":
+		consumer, err := c.buildReplayConsumer()
+		if err != nil {
+			return err
+		}
+		c.voteConsumer = consumer
+	default:
+		return fmt.Errorf("unknown vote source: %s", c.config.Consumer.Source)
+	}
+
+	return nil
+}
+
+func (c *Container) buildReplayConsumer() (port.VoteConsumerPort, error) {
+	cfg := c.config.Consumer
+
+	if cfg.ReplayFile == "" {
+		return nil, fmt.Errorf("replay file is required for the file vote source")
+	}
+
+	format, err := replay.ParseFormat(cfg.ReplayFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return replay.NewFileVoteConsumer(replay.Options{
+		Path:             cfg.ReplayFile,
+		Format:           format,
+		CheckpointPath:   cfg.ReplayCheckpoint,
+		StartOffset:      cfg.ReplayOffset,
+		ProgressInterval: cfg.ReplayProgressInterval,
+		Buffer:           c.config.Kafka.BatchSize,
+		DryRun:           cfg.ReplayDryRun,
+	}, messaging.NewDecoderRegistry()), nil
+}
+
+func (c *Container) buildKafkaConsumer() port.VoteConsumerPort {
+	decoders := messaging.NewDecoderRegistry()
+	decoders.SetDefaultContentType(c.config.Kafka.DefaultContentType)
+
+	if registry := c.buildSchemaRegistry(); registry != nil {
+		decoders.RegisterContentType(
+			messaging.ContentTypeAvro,
+			messaging.NewAvroVoteDecoder(registry, c.config.SchemaRegistry.Timeout),
+		)
+	}
+
+	return messaging.NewKafkaVoteConsumer(&c.config.Kafka, decoders)
+}
+
+func (c *Container) buildRedisConsumer() port.VoteConsumerPort {
+	cfg := c.config.Redis
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	return messaging.NewRedisVoteConsumer(client, &cfg, c.config.Kafka.BatchSize, messaging.NewDecoderRegistry())
+}
+
+func (c *Container) buildSchemaRegistry() schemaregistry.SchemaRegistry {
+	cfg := c.config.SchemaRegistry
+
+	switch {
+	case cfg.URL != "":
+		log.Printf("Using schema registry at %s", cfg.URL)
+		return schemaregistry.NewHTTPSchemaRegistry(cfg.URL, cfg.Username, cfg.Password, cfg.Timeout)
+	case cfg.Dir != "":
+		log.Printf("Using file schema registry at %s", cfg.Dir)
+		return schemaregistry.NewFileSchemaRegistry(cfg.Dir)
+	}
+
+	return nil
+}
+
+func (c *Container) buildPublishers() error {
+	outbox := c.config.Outbox.Enabled && c.database != nil
+
+	if outbox {
+		if c.config.Kafka.ResultTopic == "" {
+			return fmt.Errorf("outbox requires a result topic")
+		}
+
+		c.outboxRelay = persistence.NewOutboxRelay(
+			c.database,
+			messaging.NewKafkaOutboxPublisher(c.config.Kafka.Brokers, c.config.Kafka.ResultTopic),
+			&c.config.Outbox,
+		)
+	}
+
+	if c.resultPublisher != nil || c.config.Kafka.ResultTopic == "" {
+		return nil
+	}
+
+	// with the outbox on, every result event goes through vote_outbox so the
+	// relay keeps VoteFailed in order with VoteProcessed
+	if outbox {
+		c.resultPublisher = persistence.NewPostgresOutboxResultPublisher(c.database)
+		return nil
+	}
+
+	c.resultPublisher = messaging.NewKafkaVoteResultPublisher(c.config.Kafka.Brokers, c.config.Kafka.ResultTopic)
 
 	return nil
 }
@@ -101,7 +308,20 @@ func (c *Container) buildRepositories() error {
 func (c *Container) buildUseCases() error {
 	c.voteProcessor = usecase.NewVoteProcessorUsecase(
 		c.voteRepository,
+		c.resultPublisher,
+		c.config.Kafka.BatchSize,
+	)
+
+	return nil
+}
+
+func (c *Container) buildDispatcher() error {
+	c.dispatcher = dispatcher.NewDispatcher(
+		c.voteConsumer,
+		c.voteProcessor,
+		c.config.Kafka.Workers,
 		c.config.Kafka.BatchSize,
+		c.config.Kafka.BatchLinger,
 	)
 
 	return nil
@@ -110,10 +330,12 @@ func (c *Container) buildUseCases() error {
 func (c *Container) performHealthCheck() error {
 	log.Println("Performing health checks...")
 
-	if err := c.database.Health(); err != nil {
-		return fmt.Errorf("database unhealthy: %w", err)
+	if c.database != nil {
+		if err := c.database.Health(); err != nil {
+			return fmt.Errorf("database unhealthy: %w", err)
+		}
+		log.Println("Database health check passed")
 	}
-	log.Println("Database health check passed")
 
 	log.Println("All health checks passed")
 	c.isHealthy = true
@@ -130,12 +352,18 @@ func (c *Container) Start(ctx context.Context) error {
 		return fmt.Errorf("container not healthy")
 	}
 
-	// TODO implementar inicio do worker
-
 	log.Println("Starting application...")
 
 	c.logConfiguration()
 
+	if err := c.dispatcher.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start dispatcher: %w", err)
+	}
+
+	if c.outboxRelay != nil {
+		c.outboxRelay.Start(ctx)
+	}
+
 	log.Println("Application started successfully!")
 	return nil
 }
@@ -143,7 +371,13 @@ func (c *Container) Start(ctx context.Context) error {
 func (c *Container) Stop() {
 	log.Println("Stopping application...")
 
-	// TODO implementar parada do worker
+	if c.dispatcher != nil {
+		c.dispatcher.Stop()
+	}
+
+	if c.outboxRelay != nil {
+		c.outboxRelay.Stop()
+	}
 
 	log.Println("Application stopped successfully")
 }
@@ -151,6 +385,24 @@ func (c *Container) Stop() {
 func (c *Container) Close() error {
 	log.Println("Closing container resources...")
 
+	if c.voteConsumer != nil {
+		if err := c.voteConsumer.Close(); err != nil {
+			log.Printf("Error closing vote consumer: %v", err)
+		}
+	}
+
+	if c.resultPublisher != nil {
+		if err := c.resultPublisher.Close(); err != nil {
+			log.Printf("Error closing result publisher: %v", err)
+		}
+	}
+
+	if c.outboxRelay != nil {
+		if err := c.outboxRelay.Close(); err != nil {
+			log.Printf("Error closing outbox relay: %v", err)
+		}
+	}
+
 	if c.database != nil {
 		if err := c.database.Close(); err != nil {
 			log.Printf("Error closing database: %v", err)
@@ -166,11 +418,24 @@ func (c *Container) logConfiguration() {
 	cfg := c.config
 	log.Printf("Configuration Summary:")
 	log.Printf("   Environment: %s", cfg.App.Environment)
+	log.Printf("   ID Strategy: %s", cfg.App.IDStrategy)
 	log.Printf("   Database: %s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+	log.Printf("   Bulk Mode: %s", cfg.Database.BulkMode)
+	log.Printf("   Bulk Chunk Size: %d (per-chunk transactions: %t)", cfg.Database.BulkChunkSize, cfg.Database.BulkChunkTx)
+	log.Printf("   Insert Only: %t", cfg.Database.InsertOnly)
+	log.Printf("   Vote Source: %s", cfg.Consumer.Source)
 	log.Printf("   Kafka Topic: %s", cfg.Kafka.Topic)
 	log.Printf("   Consumer Group: %s", cfg.Kafka.ConsumerGroup)
 	log.Printf("   Batch Size: %d", cfg.Kafka.BatchSize)
+	log.Printf("   Batch Linger: %s", cfg.Kafka.BatchLinger)
 	log.Printf("   Workers: %d", cfg.Kafka.Workers)
+	log.Printf("   Commit Interval: %s", cfg.Kafka.CommitInterval)
+	log.Printf("   Dead Letter Topic: %s", cfg.Kafka.DeadLetterTopic)
+	log.Printf("   Result Topic: %s", cfg.Kafka.ResultTopic)
+	log.Printf("   Reply Topic Prefixes: %s", strings.Join(cfg.Kafka.ReplyTopicPrefixes, ", "))
+	log.Printf("   Outbox Enabled: %t", cfg.Outbox.Enabled)
+	log.Printf("   Retry: %d tiers, backoff %s x%d, max %d attempts",
+		cfg.Kafka.RetryTiers, cfg.Kafka.RetryBackoff, cfg.Kafka.RetryMultiplier, cfg.Kafka.RetryMaxAttempts)
 }
 
 func (c *Container) IsReady() bool {
@@ -0,0 +1,397 @@
+package container_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/container"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/memory"
+)
+
+const settleTimeout = 5 * time.Second
+
+type pipeline struct {
+	app        *container.Container
+	consumer   *memory.InMemoryVoteConsumer
+	repository *memory.InMemoryVoteRepository
+	publisher  *memory.InMemoryVoteResultPublisher
+}
+
+func newPipeline(t *testing.T, batchSize, workers int, linger time.Duration, opts memory.ConsumerOptions) *pipeline {
+	t.Helper()
+
+	cfg := &config.Config{
+		Kafka: config.KafkaConfig{
+			BatchSize:   batchSize,
+			BatchLinger: linger,
+			Workers:     workers,
+		},
+	}
+
+	consumer := memory.NewInMemoryVoteConsumer(opts)
+	repository := memory.NewInMemoryVoteRepository()
+	publisher := memory.NewInMemoryVoteResultPublisher()
+
+	app := container.NewContainer(cfg,
+		container.WithVoteConsumer(consumer),
+		container.WithVoteRepository(repository),
+		container.WithResultPublisher(publisher),
+	)
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		app.Stop()
+		app.Close()
+	})
+
+	return &pipeline{app: app, consumer: consumer, repository: repository, publisher: publisher}
+}
+
+func (p *pipeline) start(t *testing.T) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	if err := p.app.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+}
+
+// drain closes the input and waits for the dispatcher to flush and stop
+func (p *pipeline) drain() {
+	p.consumer.Finish()
+	p.app.Stop()
+}
+
+func newVotes(n int) []*entity.Vote {
+	votes := make([]*entity.Vote, n)
+	for i := range votes {
+		votes[i] = entity.NewVoteFromData(
+			fmt.Sprintf("vote-%03d", i),
+			i+1,
+			"session-1",
+			time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			entity.VoteStatusReceived,
+		)
+	}
+	return votes
+}
+
+func TestPipelineFlushesFullBatchesAndRemainderOnShutdown(t *testing.T) {
+	p := newPipeline(t, 3, 1, time.Hour, memory.ConsumerOptions{})
+	p.start(t)
+
+	p.consumer.Publish(newVotes(7)...)
+	p.drain()
+
+	if got, want := p.repository.Batches(), []int{3, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 7 {
+		t.Errorf("acked = %d, want 7", got)
+	}
+	if got := p.repository.Count(); got != 7 {
+		t.Errorf("stored votes = %d, want 7", got)
+	}
+}
+
+func TestPipelineFlushesPartialBatchAfterLinger(t *testing.T) {
+	p := newPipeline(t, 100, 1, 50*time.Millisecond, memory.ConsumerOptions{})
+	p.start(t)
+
+	p.consumer.Publish(newVotes(2)...)
+
+	if err := p.consumer.WaitForSettlements(2, settleTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.repository.Batches(), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+
+	p.drain()
+}
+
+func TestPipelineMarksSavedVotesProcessed(t *testing.T) {
+	p := newPipeline(t, 10, 2, 20*time.Millisecond, memory.ConsumerOptions{})
+	p.start(t)
+
+	votes := newVotes(5)
+	votes[0].Status = entity.VoteStatusSent
+
+	p.consumer.Publish(votes...)
+	p.drain()
+
+	for _, settlement := range p.consumer.Settlements() {
+		vote := settlement.Vote
+		if settlement.Outcome != memory.OutcomeAck {
+			t.Errorf("vote %s settled with %s, want ack", vote.ID, settlement.Outcome)
+		}
+		if vote.Status != entity.VoteStatusProcessed {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, entity.VoteStatusProcessed)
+		}
+		if vote.ProcessedAt == nil {
+			t.Errorf("vote %s has no ProcessedAt", vote.ID)
+		}
+		if vote.HasError() {
+			t.Errorf("vote %s has processing error %q", vote.ID, *vote.ProcessingError)
+		}
+		if _, ok := p.repository.Get(vote.ID); !ok {
+			t.Errorf("vote %s was not saved", vote.ID)
+		}
+	}
+}
+
+func TestPipelineNacksBatchWhenRepositoryFails(t *testing.T) {
+	p := newPipeline(t, 2, 1, time.Hour, memory.ConsumerOptions{})
+	p.repository.FailNext(1, errors.New("connection reset"))
+	p.start(t)
+
+	p.consumer.Publish(newVotes(4)...)
+	p.drain()
+
+	nacked := p.consumer.Settled(memory.OutcomeNack)
+	if len(nacked) != 2 {
+		t.Fatalf("nacked = %d, want 2", len(nacked))
+	}
+	for _, settlement := range nacked {
+		vote := settlement.Vote
+		if vote.Status != entity.VoteStatusFailed {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, entity.VoteStatusFailed)
+		}
+		if !vote.HasError() {
+			t.Errorf("vote %s has no processing error", vote.ID)
+		}
+		if settlement.Err == nil {
+			t.Errorf("vote %s nacked without a reason", vote.ID)
+		}
+	}
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 2 {
+		t.Errorf("acked = %d, want 2", got)
+	}
+	if got := p.repository.Count(); got != 2 {
+		t.Errorf("stored votes = %d, want 2", got)
+	}
+}
+
+func TestPipelineRetriesFailedVote(t *testing.T) {
+	p := newPipeline(t, 1, 1, time.Hour, memory.ConsumerOptions{})
+	p.repository.FailNext(1, errors.New("deadlock detected"))
+	p.start(t)
+
+	vote := newVotes(1)[0]
+	p.consumer.Publish(vote)
+	if err := p.consumer.WaitForSettlements(1, settleTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if vote.Status != entity.VoteStatusFailed {
+		t.Fatalf("status after failure = %s, want %s", vote.Status, entity.VoteStatusFailed)
+	}
+
+	// a redelivery carries the FAILED vote back into the pipeline
+	p.consumer.Publish(vote)
+	p.drain()
+
+	if vote.Status != entity.VoteStatusProcessed {
+		t.Errorf("status after retry = %s, want %s", vote.Status, entity.VoteStatusProcessed)
+	}
+	if vote.HasError() {
+		t.Errorf("processing error not cleared: %q", *vote.ProcessingError)
+	}
+}
+
+func TestPipelineRejectsInvalidVotes(t *testing.T) {
+	p := newPipeline(t, 10, 1, time.Hour, memory.ConsumerOptions{})
+	p.start(t)
+
+	votes := newVotes(3)
+	votes[1].ParticipantID = 0
+
+	p.consumer.Publish(votes...)
+	p.drain()
+
+	rejected := p.consumer.Settled(memory.OutcomeReject)
+	if len(rejected) != 1 || rejected[0].Vote.ID != votes[1].ID {
+		t.Fatalf("rejected = %+v, want only %s", rejected, votes[1].ID)
+	}
+	if rejected[0].Vote.Status != entity.VoteStatusReceived {
+		t.Errorf("rejected vote status = %s, want %s", rejected[0].Vote.Status, entity.VoteStatusReceived)
+	}
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 2 {
+		t.Errorf("acked = %d, want 2", got)
+	}
+	if _, ok := p.repository.Get(votes[1].ID); ok {
+		t.Error("invalid vote was saved")
+	}
+}
+
+func TestPipelineIsolatesPoisonVotes(t *testing.T) {
+	p := newPipeline(t, 8, 1, time.Hour, memory.ConsumerOptions{})
+	p.repository.FailVotes(func(vote *entity.Vote) error {
+		if vote.ParticipantID == 3 || vote.ParticipantID == 6 {
+			return fmt.Errorf("participant %d does not exist", vote.ParticipantID)
+		}
+		return nil
+	})
+	p.start(t)
+
+	p.consumer.Publish(newVotes(8)...)
+	p.drain()
+
+	nacked := p.consumer.Settled(memory.OutcomeNack)
+	if len(nacked) != 2 {
+		t.Fatalf("nacked = %d, want 2", len(nacked))
+	}
+	for _, settlement := range nacked {
+		vote := settlement.Vote
+		want := fmt.Sprintf("participant %d does not exist", vote.ParticipantID)
+		if vote.Status != entity.VoteStatusFailed {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, entity.VoteStatusFailed)
+		}
+		if !vote.HasError() || !strings.Contains(*vote.ProcessingError, want) {
+			t.Errorf("vote %s processing error = %v, want its own error %q", vote.ID, vote.ProcessingError, want)
+		}
+	}
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 6 {
+		t.Errorf("acked = %d, want 6", got)
+	}
+	if got := p.repository.Count(); got != 6 {
+		t.Errorf("stored votes = %d, want 6", got)
+	}
+}
+
+func TestPipelinePublishesFinalStatusOfEachVote(t *testing.T) {
+	p := newPipeline(t, 2, 1, time.Hour, memory.ConsumerOptions{})
+	p.repository.FailNext(1, errors.New("connection reset"))
+	p.start(t)
+
+	votes := newVotes(5)
+	votes[4].SessionID = ""
+
+	p.consumer.Publish(votes...)
+	p.drain()
+
+	results := p.publisher.Results()
+	if len(results) != 4 {
+		t.Fatalf("published results = %d, want 4", len(results))
+	}
+
+	byStatus := make(map[entity.VoteStatus]int)
+	for _, result := range results {
+		byStatus[result.Status]++
+
+		switch result.Status {
+		case entity.VoteStatusProcessed:
+			if result.ProcessedAt == nil {
+				t.Errorf("processed vote %s published without ProcessedAt", result.ID)
+			}
+		case entity.VoteStatusFailed:
+			if !result.HasError() {
+				t.Errorf("failed vote %s published without ProcessingError", result.ID)
+			}
+		}
+	}
+
+	if byStatus[entity.VoteStatusProcessed] != 2 || byStatus[entity.VoteStatusFailed] != 2 {
+		t.Errorf("published statuses = %v, want 2 processed and 2 failed", byStatus)
+	}
+}
+
+func TestPipelineAcksVotesWhenResultPublishFails(t *testing.T) {
+	p := newPipeline(t, 3, 1, time.Hour, memory.ConsumerOptions{})
+	p.publisher.Fail(errors.New("result topic unavailable"))
+	p.start(t)
+
+	p.consumer.Publish(newVotes(3)...)
+	p.drain()
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 3 {
+		t.Errorf("acked = %d, want 3", got)
+	}
+}
+
+func TestPipelineSavesDuplicateDeliveriesOnce(t *testing.T) {
+	p := newPipeline(t, 4, 2, 20*time.Millisecond, memory.ConsumerOptions{DuplicateEvery: 2})
+	p.start(t)
+
+	p.consumer.Publish(newVotes(6)...)
+	p.drain()
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 9 {
+		t.Errorf("acked = %d, want 9", got)
+	}
+	if got := p.repository.Count(); got != 6 {
+		t.Errorf("stored votes = %d, want 6", got)
+	}
+	if got := p.repository.Duplicates(); got != 3 {
+		t.Errorf("duplicates = %d, want 3", got)
+	}
+}
+
+func TestPipelineInsertOnlyLeavesStoredVotesUntouched(t *testing.T) {
+	p := newPipeline(t, 4, 1, time.Hour, memory.ConsumerOptions{})
+	p.repository.SetInsertOnly(true)
+	p.start(t)
+
+	p.consumer.Publish(newVotes(4)...)
+	if err := p.consumer.WaitForSettlements(4, settleTimeout); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := p.repository.Get("vote-001")
+
+	// a replay of vote-001 carrying different data
+	replayed := newVotes(2)[1]
+	replayed.ParticipantID = 99
+	p.consumer.Publish(replayed)
+	p.drain()
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 5 {
+		t.Errorf("acked = %d, want 5", got)
+	}
+	if got, _ := p.repository.Get("vote-001"); !reflect.DeepEqual(got, stored) {
+		t.Errorf("stored vote = %+v, want it untouched %+v", got, stored)
+	}
+	if got := p.repository.Duplicates(); got != 1 {
+		t.Errorf("duplicates = %d, want 1", got)
+	}
+	if got := len(p.publisher.Results()); got != 4 {
+		t.Errorf("published results = %d, want 4 with the duplicate left out", got)
+	}
+}
+
+func TestPipelineSettlesEveryVoteWithSlowComponents(t *testing.T) {
+	p := newPipeline(t, 8, 4, 10*time.Millisecond, memory.ConsumerOptions{Latency: time.Millisecond})
+	p.repository.SetLatency(20 * time.Millisecond)
+	p.start(t)
+
+	p.consumer.Publish(newVotes(50)...)
+	p.drain()
+
+	if got := len(p.consumer.Settled(memory.OutcomeAck)); got != 50 {
+		t.Errorf("acked = %d, want 50", got)
+	}
+	for _, size := range p.repository.Batches() {
+		if size > 8 {
+			t.Errorf("batch of %d exceeds batch size 8", size)
+		}
+	}
+}
+
+func TestPipelineStartFailsWhenConsumerCannotConsume(t *testing.T) {
+	p := newPipeline(t, 10, 1, time.Hour, memory.ConsumerOptions{ConsumeErr: errors.New("broker unreachable")})
+
+	if err := p.app.Start(context.Background()); err == nil {
+		t.Fatal("Start() error = nil, want consumer error")
+	}
+}
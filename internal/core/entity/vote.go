@@ -16,16 +16,25 @@ type Vote struct {
 
 	ProcessedAt     *time.Time
 	ProcessingError *string
+
+	EventID     string
+	EventSource string
+	EventTime   *time.Time
 }
 
-func NewVote(participantID int, sessionID string) *Vote {
+func NewVote(participantID int, sessionID string) (*Vote, error) {
+	id, err := util.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar id do voto: %w", err)
+	}
+
 	return &Vote{
-		ID:            util.GenerateUUID(),
+		ID:            id,
 		ParticipantID: participantID,
 		SessionID:     sessionID,
 		Timestamp:     time.Now().UTC(),
 		Status:        VoteStatusReceived,
-	}
+	}, nil
 }
 
 func NewVoteFromData(id string, participantID int, sessionID string, timestamp time.Time, status VoteStatus) *Vote {
@@ -95,7 +104,7 @@ func (v *Vote) IsValid() bool {
 }
 
 func (v *Vote) CanBeProcessed() bool {
-	return v.Status == VoteStatusSent || v.Status == VoteStatusReceived
+	return v.Status == VoteStatusSent || v.Status == VoteStatusReceived || v.Status == VoteStatusFailed
 }
 
 func (v *Vote) HasError() bool {
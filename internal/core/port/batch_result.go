@@ -0,0 +1,64 @@
+package port
+
+import (
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+)
+
+type VoteOutcome string
+
+const (
+    OutcomeProcessed VoteOutcome = "processed"
+    // OutcomeDuplicate votes were already stored and were left untouched.
+    OutcomeDuplicate VoteOutcome = "duplicate"
+    // OutcomeInvalid votes were refused before saving; redelivering them
+    // will not help.
+    OutcomeInvalid VoteOutcome = "invalid"
+    // OutcomeFailed votes could not be saved; Err says why and whether it
+    // is transient.
+    OutcomeFailed VoteOutcome = "failed"
+)
+
+type VoteResult struct {
+    Vote    *entity.Vote
+    Outcome VoteOutcome
+    Err     error
+}
+
+// BatchResult holds the outcome of every vote of a batch, in the order the
+// votes were handed in.
+type BatchResult struct {
+    Results []VoteResult
+
+    Processed  int
+    Duplicates int
+    Invalid    int
+    Failed     int
+
+    StartedAt time.Time
+    Duration  time.Duration
+}
+
+func NewBatchResult(results []VoteResult, startedAt time.Time) *BatchResult {
+    batch := &BatchResult{
+        Results:   results,
+        StartedAt: startedAt,
+        Duration:  time.Since(startedAt),
+    }
+
+    for _, result := range results {
+        switch result.Outcome {
+        case OutcomeProcessed:
+            batch.Processed++
+        case OutcomeDuplicate:
+            batch.Duplicates++
+        case OutcomeInvalid:
+            batch.Invalid++
+        case OutcomeFailed:
+            batch.Failed++
+        }
+    }
+
+    return batch
+}
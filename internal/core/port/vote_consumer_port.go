@@ -7,10 +7,17 @@ import (
 )
 
 type VoteConsumerPort interface {
-    Consume(ctx context.Context) (<-chan *entity.Vote, error)
+    Consume(ctx context.Context) (<-chan VoteDelivery, error)
     Close() error
 }
 
+type VoteDelivery interface {
+    Vote() *entity.Vote
+    Ack()
+    Nack(err error)
+    Reject(err error)
+}
+
 type MessageHandler interface {
-    Handle(ctx context.Context, votes []*entity.Vote) error
+    Handle(ctx context.Context, votes []*entity.Vote) *BatchResult
 }
\ No newline at end of file
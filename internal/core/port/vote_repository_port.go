@@ -2,11 +2,106 @@ package port
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
 )
 
+// ErrDuplicateVote is returned by Save in insert-only mode when a vote with
+// the same ID is already stored; the stored vote is left untouched.
+var ErrDuplicateVote = errors.New("vote already exists")
+
 type VoteRepositoryPort interface {
-    BulkSave(ctx context.Context, votes []*entity.Vote) error
+    // BulkSave returns one ID per vote that was not inserted because its ID
+    // was already stored, which only happens in insert-only mode. The
+    // stored votes are left untouched.
+    BulkSave(ctx context.Context, votes []*entity.Vote) ([]string, error)
     Save(ctx context.Context, vote *entity.Vote) error
-}
\ No newline at end of file
+}
+
+// ChunkResult reports one chunk of a BulkSave: votes[Offset:Offset+Size].
+type ChunkResult struct {
+    Offset   int
+    Size     int
+    Duration time.Duration
+    Err      error
+}
+
+// BulkSaveError is returned by BulkSave when chunks are committed in
+// separate transactions and some of them failed. Chunks without Err were
+// committed, and the duplicates returned with the error are theirs.
+type BulkSaveError struct {
+    Chunks []ChunkResult
+}
+
+func (e *BulkSaveError) Failed() []ChunkResult {
+    var failed []ChunkResult
+    for _, chunk := range e.Chunks {
+        if chunk.Err != nil {
+            failed = append(failed, chunk)
+        }
+    }
+    return failed
+}
+
+func (e *BulkSaveError) Error() string {
+    failed := e.Failed()
+
+    reasons := make([]string, len(failed))
+    for i, chunk := range failed {
+        reasons[i] = fmt.Sprintf("votes %d-%d: %v", chunk.Offset, chunk.Offset+chunk.Size-1, chunk.Err)
+    }
+
+    return fmt.Sprintf("%d of %d chunks failed: %s", len(failed), len(e.Chunks), strings.Join(reasons, "; "))
+}
+
+func (e *BulkSaveError) Unwrap() []error {
+    var errs []error
+    for _, chunk := range e.Failed() {
+        errs = append(errs, chunk.Err)
+    }
+    return errs
+}
+
+// TransientError marks a repository failure that may succeed if retried as
+// is, such as a lost connection or a serialization conflict, as opposed to
+// votes the database will keep refusing.
+type TransientError struct {
+    Err error
+}
+
+func (e *TransientError) Error() string {
+    return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+    return e.Err
+}
+
+func IsTransient(err error) bool {
+    var transient *TransientError
+    return errors.As(err, &transient) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// RowError marks a repository failure known to be caused by the data of
+// some vote in the call, such as a data exception or an integrity
+// violation. Saving the votes apart finds the ones at fault.
+type RowError struct {
+    Err error
+}
+
+func (e *RowError) Error() string {
+    return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+    return e.Err
+}
+
+func IsRowError(err error) bool {
+    var rowErr *RowError
+    return errors.As(err, &rowErr)
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+)
+
+type VoteResultPublisherPort interface {
+    PublishResults(ctx context.Context, votes []*entity.Vote) error
+    Close() error
+}
\ No newline at end of file
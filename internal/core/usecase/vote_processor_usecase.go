@@ -2,22 +2,33 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
 )
 
+// defaultPublishTimeout bounds how long a batch waits on its result events,
+// so a stalled broker delays the batch instead of blocking it
+const defaultPublishTimeout = 5 * time.Second
+
 type VoteProcessorUsecase struct {
-    repository port.VoteRepositoryPort
-    batchSize  int
+    repository     port.VoteRepositoryPort
+    publisher      port.VoteResultPublisherPort
+    batchSize      int
+    publishTimeout time.Duration
 }
 
-func NewVoteProcessorUsecase(repository port.VoteRepositoryPort, batchSize int) *VoteProcessorUsecase {
+// publisher may be nil, in which case outcomes are not published
+func NewVoteProcessorUsecase(repository port.VoteRepositoryPort, publisher port.VoteResultPublisherPort, batchSize int) *VoteProcessorUsecase {
     return &VoteProcessorUsecase{
-		repository: repository,
-		batchSize:  batchSize,
+		repository:     repository,
+		publisher:      publisher,
+		batchSize:      batchSize,
+		publishTimeout: defaultPublishTimeout,
 	}
 }
 
@@ -32,52 +43,174 @@ func (vp *VoteProcessorUsecase) ProcessSingleVote(ctx context.Context, vote *ent
 
     log.Printf("Processando voto: ID=%s, ParticipantID=%d", vote.ID, vote.ParticipantID)
 
-    if err := vp.repository.Save(ctx, vote); err != nil {
+    err := vp.repository.Save(ctx, vote)
+    if errors.Is(err, port.ErrDuplicateVote) {
+        log.Printf("Voto duplicado ignorado: ID=%s", vote.ID)
+        vote.MarkAsProcessed()
+        return nil
+    }
+    if err != nil {
         vote.MarkAsFailedWithError(err)
+        vp.publishResults(ctx, []*entity.Vote{vote})
         return fmt.Errorf("falha ao salvar voto: %w", err)
     }
 
     vote.MarkAsProcessed()
+    vp.publishResults(ctx, []*entity.Vote{vote})
 
     return nil
 }
 
-func (vp *VoteProcessorUsecase) ProcessVotesBatch(ctx context.Context, votes []*entity.Vote) error {
+func (vp *VoteProcessorUsecase) ProcessVotesBatch(ctx context.Context, votes []*entity.Vote) *port.BatchResult {
+    startedAt := time.Now()
+    results := make([]port.VoteResult, len(votes))
+
     if len(votes) == 0 {
-        return nil
+        return port.NewBatchResult(results, startedAt)
     }
 
     log.Printf("Processando batch de %d votos", len(votes))
 
-    validVotes := make([]*entity.Vote, 0, len(votes))
-    invalidCount := 0
+    pending := make([]*port.VoteResult, 0, len(votes))
+
+    for i, vote := range votes {
+        results[i].Vote = vote
 
-    for _, vote := range votes {
         if err := vp.validateAndPrepareVote(vote); err != nil {
             log.Printf("Voto inválido ignorado: ID=%s, erro=%v", vote.ID, err)
-            invalidCount++
+            results[i].Outcome = port.OutcomeInvalid
+            results[i].Err = err
             continue
         }
-        validVotes = append(validVotes, vote)
+        pending = append(pending, &results[i])
+    }
+
+    if len(pending) > 0 {
+        vp.save(ctx, pending)
+        vp.publishResults(ctx, votesOf(withoutDuplicates(pending)))
+    }
+
+    batch := port.NewBatchResult(results, startedAt)
+    log.Printf("Batch processado em %s: %d votos salvos, %d duplicados, %d inválidos, %d com falha",
+        batch.Duration, batch.Processed, batch.Duplicates, batch.Invalid, batch.Failed)
+
+    return batch
+}
+
+// save persists the votes of pending and records the outcome of each one,
+// marking it PROCESSED or FAILED. Duplicates are marked PROCESSED too, as
+// a vote with their ID is already stored.
+func (vp *VoteProcessorUsecase) save(ctx context.Context, pending []*port.VoteResult) {
+    duplicates, err := vp.repository.BulkSave(ctx, votesOf(pending))
+    if err == nil {
+        markSaved(pending, duplicates)
+        return
     }
 
-    if len(validVotes) == 0 {
-        return fmt.Errorf("nenhum voto válido encontrado no batch de %d votos", len(votes))
+    var bulkErr *port.BulkSaveError
+    if !errors.As(err, &bulkErr) {
+        vp.isolate(ctx, pending, err)
+        return
     }
 
-    if err := vp.repository.BulkSave(ctx, validVotes); err != nil {
-        for _, vote := range validVotes {
-            vote.MarkAsFailedWithError(err)
+    // chunks committed in their own transactions are kept; only the
+    // failed ones are isolated
+    var saved []*port.VoteResult
+    for _, chunk := range bulkErr.Chunks {
+        chunkResults := pending[chunk.Offset : chunk.Offset+chunk.Size]
+        if chunk.Err == nil {
+            saved = append(saved, chunkResults...)
+            continue
         }
-        return fmt.Errorf("falha ao salvar batch: %w", err)
+
+        vp.isolate(ctx, chunkResults, chunk.Err)
     }
+    markSaved(saved, duplicates)
+}
 
-    for _, vote := range validVotes {
-        vote.MarkAsProcessed()
+// markSaved records saved votes as processed, or as duplicates when their ID
+// is among duplicates. Duplicates hold one ID per vote that was not
+// inserted, and the first vote sharing an ID is the inserted one, so
+// matching goes from the back.
+func markSaved(saved []*port.VoteResult, duplicates []string) {
+    remaining := make(map[string]int, len(duplicates))
+    for _, id := range duplicates {
+        remaining[id]++
     }
 
-    log.Printf("Batch processado com sucesso: %d votos salvos, %d inválidos", len(validVotes), invalidCount)
-    return nil
+    for i := len(saved) - 1; i >= 0; i-- {
+        result := saved[i]
+        result.Vote.MarkAsProcessed()
+        result.Outcome = port.OutcomeProcessed
+
+        if remaining[result.Vote.ID] > 0 {
+            remaining[result.Vote.ID]--
+            result.Outcome = port.OutcomeDuplicate
+        }
+    }
+}
+
+// isolate bisects votes that failed to save together until the votes the
+// database refuses are found, and fails each of those with its own error.
+// Any error that is not transient is bisected, since one bad vote is
+// enough to fail a whole statement even when the error is not a recognised
+// row error. A transient error fails all of them at once, since it does not
+// depend on which votes are saved together.
+func (vp *VoteProcessorUsecase) isolate(ctx context.Context, pending []*port.VoteResult, err error) {
+    if len(pending) == 1 || port.IsTransient(err) {
+        for _, result := range pending {
+            result.Vote.MarkAsFailedWithError(err)
+            result.Outcome = port.OutcomeFailed
+            result.Err = err
+        }
+        return
+    }
+
+    log.Printf("Isolando falha em %d votos: %v", len(pending), err)
+
+    mid := len(pending) / 2
+    vp.save(ctx, pending[:mid])
+    vp.save(ctx, pending[mid:])
+}
+
+// withoutDuplicates drops duplicates, whose outcome was already published
+// when the stored vote was saved.
+func withoutDuplicates(results []*port.VoteResult) []*port.VoteResult {
+    kept := make([]*port.VoteResult, 0, len(results))
+    for _, result := range results {
+        if result.Outcome != port.OutcomeDuplicate {
+            kept = append(kept, result)
+        }
+    }
+    return kept
+}
+
+func votesOf(results []*port.VoteResult) []*entity.Vote {
+    votes := make([]*entity.Vote, len(results))
+    for i, result := range results {
+        votes[i] = result.Vote
+    }
+    return votes
+}
+
+func (vp *VoteProcessorUsecase) Handle(ctx context.Context, votes []*entity.Vote) *port.BatchResult {
+    return vp.ProcessVotesBatch(ctx, votes)
+}
+
+// publishResults is best effort: the votes are already persisted, so a
+// publish failure, or one that outlasts publishTimeout, is logged rather
+// than failing the batch.
+func (vp *VoteProcessorUsecase) publishResults(ctx context.Context, votes []*entity.Vote) {
+    if vp.publisher == nil || len(votes) == 0 {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, vp.publishTimeout)
+    defer cancel()
+
+    if err := vp.publisher.PublishResults(ctx, votes); err != nil {
+        log.Printf("Falha ao publicar resultado de %d votos: %v", len(votes), err)
+    }
 }
 
 func (vp *VoteProcessorUsecase) validateAndPrepareVote(vote *entity.Vote) error {
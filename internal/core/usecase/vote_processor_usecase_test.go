@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+// stubRepository fails the calls for which fail returns an error, reports
+// votes whose ID is in stored as duplicates and records the size of every
+// call.
+type stubRepository struct {
+	fail   func(votes []*entity.Vote) error
+	stored map[string]bool
+	calls  []int
+}
+
+func (r *stubRepository) BulkSave(ctx context.Context, votes []*entity.Vote) ([]string, error) {
+	r.calls = append(r.calls, len(votes))
+	if err := r.fail(votes); err != nil {
+		return nil, err
+	}
+
+	var duplicates []string
+	for _, vote := range votes {
+		if r.stored[vote.ID] {
+			duplicates = append(duplicates, vote.ID)
+		}
+	}
+	return duplicates, nil
+}
+
+func (r *stubRepository) Save(ctx context.Context, vote *entity.Vote) error {
+	duplicates, err := r.BulkSave(ctx, []*entity.Vote{vote})
+	if err == nil && len(duplicates) > 0 {
+		return port.ErrDuplicateVote
+	}
+	return err
+}
+
+func failAlways(err error) func([]*entity.Vote) error {
+	return func([]*entity.Vote) error { return err }
+}
+
+func newVotes(n int) []*entity.Vote {
+	votes := make([]*entity.Vote, n)
+	for i := range votes {
+		votes[i] = entity.NewVoteFromData(
+			fmt.Sprintf("vote-%03d", i),
+			i+1,
+			"session-1",
+			time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			entity.VoteStatusReceived,
+		)
+	}
+	return votes
+}
+
+func TestProcessVotesBatchMarksOnlyFailedChunks(t *testing.T) {
+	chunkErr := &port.TransientError{Err: errors.New("deadlock detected")}
+	repository := &stubRepository{fail: failAlways(&port.BulkSaveError{Chunks: []port.ChunkResult{
+		{Offset: 0, Size: 2},
+		{Offset: 2, Size: 2, Err: chunkErr},
+		{Offset: 4, Size: 1},
+	}})}
+	processor := NewVoteProcessorUsecase(repository, nil, 5)
+
+	votes := newVotes(5)
+	batch := processor.ProcessVotesBatch(context.Background(), votes)
+	if batch.Processed != 3 || batch.Failed != 2 {
+		t.Fatalf("processed = %d, failed = %d, want 3 and 2", batch.Processed, batch.Failed)
+	}
+
+	for i, result := range batch.Results {
+		vote := result.Vote
+		want := entity.VoteStatusProcessed
+		if i == 2 || i == 3 {
+			want = entity.VoteStatusFailed
+			if result.Outcome != port.OutcomeFailed || !errors.Is(result.Err, chunkErr) {
+				t.Errorf("vote %s outcome = %s (%v), want failed with %v", vote.ID, result.Outcome, result.Err, chunkErr)
+			}
+		}
+		if vote.Status != want {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, want)
+		}
+		if want == entity.VoteStatusFailed && (!vote.HasError() || *vote.ProcessingError != chunkErr.Error()) {
+			t.Errorf("vote %s processing error = %v, want %q", vote.ID, vote.ProcessingError, chunkErr)
+		}
+	}
+}
+
+func TestProcessVotesBatchFailsWholeBatchOnTransientError(t *testing.T) {
+	repository := &stubRepository{fail: failAlways(&port.TransientError{Err: errors.New("connection reset")})}
+	processor := NewVoteProcessorUsecase(repository, nil, 3)
+
+	votes := newVotes(3)
+	if batch := processor.ProcessVotesBatch(context.Background(), votes); batch.Failed != 3 {
+		t.Fatalf("failed = %d, want 3", batch.Failed)
+	}
+
+	if !reflect.DeepEqual(repository.calls, []int{3}) {
+		t.Errorf("BulkSave calls = %v, want a single call", repository.calls)
+	}
+	for _, vote := range votes {
+		if vote.Status != entity.VoteStatusFailed {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, entity.VoteStatusFailed)
+		}
+	}
+}
+
+func TestProcessVotesBatchBisectsToPoisonVote(t *testing.T) {
+	repository := &stubRepository{fail: func(votes []*entity.Vote) error {
+		for _, vote := range votes {
+			if vote.ID == "vote-005" {
+				return &port.RowError{Err: errors.New("violates foreign key constraint")}
+			}
+		}
+		return nil
+	}}
+	processor := NewVoteProcessorUsecase(repository, nil, 8)
+
+	votes := newVotes(8)
+	batch := processor.ProcessVotesBatch(context.Background(), votes)
+	if batch.Processed != 7 || batch.Failed != 1 {
+		t.Fatalf("processed = %d, failed = %d, want 7 and 1", batch.Processed, batch.Failed)
+	}
+
+	for _, vote := range votes {
+		if vote.ID == "vote-005" {
+			if vote.Status != entity.VoteStatusFailed || !vote.HasError() {
+				t.Errorf("poison vote status = %s, error = %v, want FAILED with its error", vote.Status, vote.ProcessingError)
+			}
+			if result := batch.Results[5]; result.Outcome != port.OutcomeFailed || result.Err == nil {
+				t.Errorf("poison vote outcome = %s (%v), want failed with its error", result.Outcome, result.Err)
+			}
+			continue
+		}
+		if vote.Status != entity.VoteStatusProcessed {
+			t.Errorf("vote %s status = %s, want %s", vote.ID, vote.Status, entity.VoteStatusProcessed)
+		}
+	}
+
+	// depth first: 8, then 4 saved and 4 failing, then 2 failing, 1 saved
+	// and 1 failing, then the last 2 saved
+	if want := []int{8, 4, 4, 2, 1, 1, 2}; !reflect.DeepEqual(repository.calls, want) {
+		t.Errorf("BulkSave calls = %v, want %v", repository.calls, want)
+	}
+}
+
+func TestProcessVotesBatchBisectsOnUnclassifiedError(t *testing.T) {
+	poisonErr := errors.New("value too long for type character varying(255)")
+	repository := &stubRepository{fail: func(votes []*entity.Vote) error {
+		for _, vote := range votes {
+			if vote.ID == "vote-002" {
+				return poisonErr
+			}
+		}
+		return nil
+	}}
+	processor := NewVoteProcessorUsecase(repository, nil, 4)
+
+	votes := newVotes(4)
+	batch := processor.ProcessVotesBatch(context.Background(), votes)
+	if batch.Processed != 3 || batch.Failed != 1 {
+		t.Fatalf("processed = %d, failed = %d, want 3 and 1", batch.Processed, batch.Failed)
+	}
+
+	for i, result := range batch.Results {
+		if i == 2 {
+			if result.Outcome != port.OutcomeFailed || !errors.Is(result.Err, poisonErr) {
+				t.Errorf("poison vote outcome = %s (%v), want failed with %v", result.Outcome, result.Err, poisonErr)
+			}
+			continue
+		}
+		if result.Outcome != port.OutcomeProcessed {
+			t.Errorf("vote %s outcome = %s, want %s", result.Vote.ID, result.Outcome, port.OutcomeProcessed)
+		}
+	}
+
+	if want := []int{4, 2, 2, 1, 1}; !reflect.DeepEqual(repository.calls, want) {
+		t.Errorf("BulkSave calls = %v, want %v", repository.calls, want)
+	}
+}
+
+func TestProcessVotesBatchReportsEachOutcomeInOrder(t *testing.T) {
+	repository := &stubRepository{fail: failAlways(nil)}
+	processor := NewVoteProcessorUsecase(repository, nil, 4)
+
+	votes := newVotes(4)
+	votes[1].ParticipantID = 0
+	votes[3].Status = entity.VoteStatusProcessed
+
+	batch := processor.ProcessVotesBatch(context.Background(), votes)
+
+	want := []port.VoteOutcome{port.OutcomeProcessed, port.OutcomeInvalid, port.OutcomeProcessed, port.OutcomeInvalid}
+	for i, result := range batch.Results {
+		if result.Vote != votes[i] {
+			t.Errorf("result %d is for vote %s, want %s", i, result.Vote.ID, votes[i].ID)
+		}
+		if result.Outcome != want[i] {
+			t.Errorf("vote %s outcome = %s, want %s", votes[i].ID, result.Outcome, want[i])
+		}
+		if (result.Outcome == port.OutcomeInvalid) != (result.Err != nil) {
+			t.Errorf("vote %s outcome = %s with error %v", votes[i].ID, result.Outcome, result.Err)
+		}
+	}
+
+	if batch.Processed != 2 || batch.Invalid != 2 || batch.Failed != 0 {
+		t.Errorf("counts = %d/%d/%d, want 2 processed, 2 invalid, 0 failed", batch.Processed, batch.Invalid, batch.Failed)
+	}
+	if batch.StartedAt.IsZero() || batch.Duration < 0 {
+		t.Errorf("timing = %s from %s, want it recorded", batch.Duration, batch.StartedAt)
+	}
+	if !reflect.DeepEqual(repository.calls, []int{2}) {
+		t.Errorf("BulkSave calls = %v, want only the 2 valid votes", repository.calls)
+	}
+}
+
+func TestProcessVotesBatchReportsDuplicatesSeparately(t *testing.T) {
+	repository := &stubRepository{fail: failAlways(nil), stored: map[string]bool{"vote-001": true}}
+	processor := NewVoteProcessorUsecase(repository, nil, 3)
+
+	votes := newVotes(3)
+	batch := processor.ProcessVotesBatch(context.Background(), votes)
+
+	if batch.Processed != 2 || batch.Duplicates != 1 {
+		t.Fatalf("processed = %d, duplicates = %d, want 2 and 1", batch.Processed, batch.Duplicates)
+	}
+	if result := batch.Results[1]; result.Outcome != port.OutcomeDuplicate || result.Err != nil {
+		t.Errorf("vote-001 outcome = %s (%v), want duplicate", result.Outcome, result.Err)
+	}
+}
+
+func TestMarkSavedTreatsFirstVoteOfRepeatedIDAsInserted(t *testing.T) {
+	votes := newVotes(3)
+	votes[2].ID = votes[0].ID
+
+	saved := make([]*port.VoteResult, len(votes))
+	for i, vote := range votes {
+		vote.MarkAsProcessing()
+		saved[i] = &port.VoteResult{Vote: vote}
+	}
+
+	markSaved(saved, []string{votes[0].ID})
+
+	want := []port.VoteOutcome{port.OutcomeProcessed, port.OutcomeProcessed, port.OutcomeDuplicate}
+	for i, result := range saved {
+		if result.Outcome != want[i] {
+			t.Errorf("vote %d outcome = %s, want %s", i, result.Outcome, want[i])
+		}
+		if result.Vote.Status != entity.VoteStatusProcessed {
+			t.Errorf("vote %d status = %s, want %s", i, result.Vote.Status, entity.VoteStatusProcessed)
+		}
+	}
+}
+
+func TestProcessSingleVoteIgnoresDuplicate(t *testing.T) {
+	repository := &stubRepository{fail: failAlways(nil), stored: map[string]bool{"vote-000": true}}
+	processor := NewVoteProcessorUsecase(repository, nil, 1)
+
+	vote := newVotes(1)[0]
+	if err := processor.ProcessSingleVote(context.Background(), vote); err != nil {
+		t.Fatalf("ProcessSingleVote() error = %v, want nil for a duplicate", err)
+	}
+	if vote.HasError() {
+		t.Errorf("duplicate vote has processing error %q", *vote.ProcessingError)
+	}
+}
+
+// blockingPublisher never publishes, it waits until its context is done.
+type blockingPublisher struct {
+	err error
+}
+
+func (p *blockingPublisher) PublishResults(ctx context.Context, votes []*entity.Vote) error {
+	<-ctx.Done()
+	p.err = ctx.Err()
+	return p.err
+}
+
+func (p *blockingPublisher) Close() error {
+	return nil
+}
+
+func TestProcessVotesBatchBoundsStalledPublish(t *testing.T) {
+	repository := &stubRepository{fail: failAlways(nil)}
+	publisher := &blockingPublisher{}
+	processor := NewVoteProcessorUsecase(repository, publisher, 3)
+	processor.publishTimeout = 20 * time.Millisecond
+
+	done := make(chan *port.BatchResult, 1)
+	go func() {
+		done <- processor.ProcessVotesBatch(context.Background(), newVotes(3))
+	}()
+
+	select {
+	case batch := <-done:
+		if batch.Processed != 3 {
+			t.Errorf("processed = %d, want 3", batch.Processed)
+		}
+		if !errors.Is(publisher.err, context.DeadlineExceeded) {
+			t.Errorf("publish error = %v, want %v", publisher.err, context.DeadlineExceeded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ProcessVotesBatch() blocked on a stalled publisher")
+	}
+}
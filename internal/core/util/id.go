@@ -2,20 +2,154 @@ package util
 
 import (
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
-func GenerateUUID() string {
-	b := make([]byte, 16)
-	_, err := rand.Read(b)
-	if err != nil {
-		return fmt.Sprintf("error-%d", time.Now().UnixNano())
+type IDStrategy string
+
+const (
+	IDStrategyUUIDv4 IDStrategy = "uuidv4"
+	IDStrategyUUIDv7 IDStrategy = "uuidv7"
+	IDStrategyULID   IDStrategy = "ulid"
+)
+
+var idStrategy atomic.Value
+
+func init() {
+	idStrategy.Store(IDStrategyUUIDv7)
+}
+
+func ParseIDStrategy(s string) (IDStrategy, error) {
+	strategy := IDStrategy(strings.ToLower(strings.TrimSpace(s)))
+	switch strategy {
+	case IDStrategyUUIDv4, IDStrategyUUIDv7, IDStrategyULID:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown id strategy %q", s)
+}
+
+// SetIDStrategy selects the generator behind NewID for the whole process.
+func SetIDStrategy(strategy IDStrategy) {
+	idStrategy.Store(strategy)
+}
+
+// NewID generates an ID with the configured strategy, UUIDv7 by default.
+func NewID() (string, error) {
+	switch idStrategy.Load().(IDStrategy) {
+	case IDStrategyUUIDv4:
+		return GenerateUUID()
+	case IDStrategyULID:
+		return GenerateULID()
+	default:
+		return GenerateUUIDv7()
+	}
+}
+
+func GenerateUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
 	}
 
 	b[6] = (b[6] & 0x0f) | 0x40
 	b[8] = (b[8] & 0x3f) | 0x80
 
+	return formatUUID(b), nil
+}
+
+// GenerateUUIDv7 returns a UUID (RFC 9562 version 7) that starts with the
+// Unix time in milliseconds, so IDs sort by creation time and inserts land
+// at the end of the index instead of at random pages. The 12 bits after the
+// version hold the sub-millisecond fraction for finer ordering.
+func GenerateUUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+
+	now := time.Now()
+	ms := uint64(now.UnixMilli())
+	fraction := uint16(uint64(now.Nanosecond()%int(time.Millisecond)) * 4096 / uint64(time.Millisecond))
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(fraction>>8)
+	b[7] = byte(fraction)
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return formatUUID(b), nil
+}
+
+// GenerateUUIDv5 derives a name-based UUID (RFC 4122 version 5), so the
+// same namespace and name always yield the same ID.
+func GenerateUUIDv5(namespace [16]byte, name string) string {
+	hash := sha1.New()
+	hash.Write(namespace[:])
+	hash.Write([]byte(name))
+
+	var b [16]byte
+	copy(b[:], hash.Sum(nil))
+
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return formatUUID(b)
+}
+
+func ParseUUID(s string) ([16]byte, error) {
+	var b [16]byte
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return b, fmt.Errorf("invalid uuid %q", s)
+	}
+
+	raw := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(b[:], []byte(raw)); err != nil {
+		return b, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+
+	return b, nil
+}
+
+func UUIDVersion(s string) (int, error) {
+	b, err := ParseUUID(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(b[6] >> 4), nil
+}
+
+// IDTimestamp extracts the creation time embedded in a UUIDv7 or ULID.
+// Other IDs carry no timestamp and return an error.
+func IDTimestamp(id string) (time.Time, error) {
+	if len(id) == ulidLength {
+		return ULIDTimestamp(id)
+	}
+
+	b, err := ParseUUID(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if version := b[6] >> 4; version != 7 {
+		return time.Time{}, fmt.Errorf("uuid version %d has no timestamp", version)
+	}
+
+	var ms [8]byte
+	copy(ms[2:], b[0:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(ms[:]))).UTC(), nil
+}
+
+func formatUUID(b [16]byte) string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x",
 		b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
-}
\ No newline at end of file
+}
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateUUIDv7EmbedsCreationTime(t *testing.T) {
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	id, err := GenerateUUIDv7()
+	if err != nil {
+		t.Fatalf("GenerateUUIDv7() error = %v", err)
+	}
+	after := time.Now().UTC()
+
+	if version, err := UUIDVersion(id); err != nil || version != 7 {
+		t.Fatalf("UUIDVersion(%s) = %d, %v, want 7", id, version, err)
+	}
+
+	ts, err := IDTimestamp(id)
+	if err != nil {
+		t.Fatalf("IDTimestamp(%s) error = %v", id, err)
+	}
+	if ts.Before(before) || ts.After(after) {
+		t.Errorf("IDTimestamp(%s) = %s, want between %s and %s", id, ts, before, after)
+	}
+}
+
+func TestGenerateUUIDv7SortsByTime(t *testing.T) {
+	first, _ := GenerateUUIDv7()
+	time.Sleep(2 * time.Millisecond)
+	second, _ := GenerateUUIDv7()
+
+	if first >= second {
+		t.Errorf("%s does not sort before %s", first, second)
+	}
+}
+
+func TestULIDRoundTrip(t *testing.T) {
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	id, err := GenerateULID()
+	if err != nil {
+		t.Fatalf("GenerateULID() error = %v", err)
+	}
+
+	if len(id) != 26 {
+		t.Fatalf("len(%s) = %d, want 26", id, len(id))
+	}
+
+	b, err := ParseULID(id)
+	if err != nil {
+		t.Fatalf("ParseULID(%s) error = %v", id, err)
+	}
+	if encoded := encodeULID(b); encoded != id {
+		t.Errorf("encodeULID(ParseULID(%s)) = %s", id, encoded)
+	}
+
+	ts, err := IDTimestamp(id)
+	if err != nil {
+		t.Fatalf("IDTimestamp(%s) error = %v", id, err)
+	}
+	if ts.Before(before) || ts.After(time.Now().UTC()) {
+		t.Errorf("IDTimestamp(%s) = %s, not around now", id, ts)
+	}
+}
+
+func TestParseULIDRejectsInvalidInput(t *testing.T) {
+	for _, id := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU"} {
+		if _, err := ParseULID(id); err == nil {
+			t.Errorf("ParseULID(%q) error = nil", id)
+		}
+	}
+}
+
+func TestGenerateUUIDv5MatchesRFCExample(t *testing.T) {
+	dns := [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+	if got, want := GenerateUUIDv5(dns, "www.example.com"), "2ed6657d-e927-568b-95e1-2665a8aea6a2"; got != want {
+		t.Errorf("GenerateUUIDv5() = %s, want %s", got, want)
+	}
+}
+
+func TestIDTimestampRejectsRandomUUID(t *testing.T) {
+	id, err := GenerateUUID()
+	if err != nil {
+		t.Fatalf("GenerateUUID() error = %v", err)
+	}
+
+	if _, err := IDTimestamp(id); err == nil {
+		t.Errorf("IDTimestamp(%s) error = nil for a v4 uuid", id)
+	}
+}
+
+func TestNewIDFollowsStrategy(t *testing.T) {
+	defer SetIDStrategy(IDStrategyUUIDv7)
+
+	strategy, err := ParseIDStrategy("ULID")
+	if err != nil {
+		t.Fatalf("ParseIDStrategy() error = %v", err)
+	}
+	SetIDStrategy(strategy)
+
+	id, err := NewID()
+	if err != nil {
+		t.Fatalf("NewID() error = %v", err)
+	}
+	if _, err := ParseULID(id); err != nil {
+		t.Errorf("NewID() = %s, not a ulid: %v", id, err)
+	}
+
+	if _, err := ParseIDStrategy("snowflake"); err == nil {
+		t.Error("ParseIDStrategy(snowflake) error = nil")
+	}
+}
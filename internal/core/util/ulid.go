@@ -0,0 +1,103 @@
+package util
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ulidLength   = 26
+	ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+var ulidDecoding = func() [256]byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = 0xff
+	}
+	lower := strings.ToLower(ulidAlphabet)
+	for i := 0; i < len(ulidAlphabet); i++ {
+		table[ulidAlphabet[i]] = byte(i)
+		table[lower[i]] = byte(i)
+	}
+	return table
+}()
+
+// GenerateULID returns a ULID: 48 bits of Unix milliseconds followed by 80
+// random bits, Crockford base32 encoded into 26 sortable characters.
+func GenerateULID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate ulid: %w", err)
+	}
+
+	ms := uint64(time.Now().UnixMilli())
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+
+	return encodeULID(b), nil
+}
+
+func ParseULID(s string) ([16]byte, error) {
+	var b [16]byte
+
+	if len(s) != ulidLength {
+		return b, fmt.Errorf("invalid ulid %q", s)
+	}
+	// 26 characters hold 130 bits, so the first one cannot exceed 7
+	if ulidDecoding[s[0]] > 7 {
+		return b, fmt.Errorf("invalid ulid %q", s)
+	}
+
+	var hi, lo uint64
+	for i := 0; i < ulidLength; i++ {
+		v := ulidDecoding[s[i]]
+		if v == 0xff {
+			return b, fmt.Errorf("invalid ulid %q", s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+
+	for i := 0; i < 8; i++ {
+		b[i] = byte(hi >> (56 - 8*i))
+		b[8+i] = byte(lo >> (56 - 8*i))
+	}
+
+	return b, nil
+}
+
+func ULIDTimestamp(s string) (time.Time, error) {
+	b, err := ParseULID(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var ms uint64
+	for _, v := range b[:6] {
+		ms = ms<<8 | uint64(v)
+	}
+	return time.UnixMilli(int64(ms)).UTC(), nil
+}
+
+func encodeULID(b [16]byte) string {
+	var hi, lo uint64
+	for i := 0; i < 8; i++ {
+		hi = hi<<8 | uint64(b[i])
+		lo = lo<<8 | uint64(b[8+i])
+	}
+
+	out := make([]byte, ulidLength)
+	for i := ulidLength - 1; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
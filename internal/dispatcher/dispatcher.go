@@ -0,0 +1,148 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+type Dispatcher struct {
+	consumer port.VoteConsumerPort
+	handler  port.MessageHandler
+
+	workers   int
+	batchSize int
+	linger    time.Duration
+
+	wg sync.WaitGroup
+}
+
+func NewDispatcher(consumer port.VoteConsumerPort, handler port.MessageHandler, workers, batchSize int, linger time.Duration) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	return &Dispatcher{
+		consumer:  consumer,
+		handler:   handler,
+		workers:   workers,
+		batchSize: batchSize,
+		linger:    linger,
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) error {
+	deliveries, err := d.consumer.Consume(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start consuming votes: %w", err)
+	}
+
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go func(id int) {
+			defer d.wg.Done()
+			d.runWorker(ctx, id, deliveries)
+		}(i + 1)
+	}
+
+	log.Printf("Dispatcher started: %d workers, batch size %d, linger %s", d.workers, d.batchSize, d.linger)
+	return nil
+}
+
+func (d *Dispatcher) Stop() {
+	d.wg.Wait()
+	log.Println("Dispatcher stopped")
+}
+
+func (d *Dispatcher) runWorker(ctx context.Context, id int, deliveries <-chan port.VoteDelivery) {
+	// votes still buffered when ctx is cancelled must be saved, so flushes
+	// outlive the consumer context
+	flushCtx := context.WithoutCancel(ctx)
+
+	batch := make([]port.VoteDelivery, 0, d.batchSize)
+	linger := time.NewTimer(d.linger)
+	linger.Stop()
+	defer linger.Stop()
+
+	flush := func(reason string) {
+		if len(batch) == 0 {
+			return
+		}
+		linger.Stop()
+		d.flush(flushCtx, id, batch, reason)
+		batch = make([]port.VoteDelivery, 0, d.batchSize)
+	}
+
+	for {
+		select {
+		case delivery, ok := <-deliveries:
+			if !ok {
+				flush("shutdown")
+				return
+			}
+
+			batch = append(batch, delivery)
+			if len(batch) == 1 {
+				linger.Reset(d.linger)
+			}
+			if len(batch) >= d.batchSize {
+				flush("size")
+			}
+
+		case <-linger.C:
+			flush("linger")
+		}
+	}
+}
+
+func (d *Dispatcher) flush(ctx context.Context, id int, deliveries []port.VoteDelivery, reason string) {
+	log.Printf("Worker %d flushing %d votes (%s)", id, len(deliveries), reason)
+
+	votes := make([]*entity.Vote, len(deliveries))
+	for i, delivery := range deliveries {
+		votes[i] = delivery.Vote()
+	}
+
+	batch := d.handler.Handle(ctx, votes)
+	if batch == nil || len(batch.Results) != len(deliveries) {
+		err := errors.New("handler returned no result for the batch")
+		log.Printf("Worker %d failed to process batch: %v", id, err)
+		for _, delivery := range deliveries {
+			delivery.Nack(err)
+		}
+		return
+	}
+
+	if batch.Duplicates > 0 || batch.Invalid > 0 || batch.Failed > 0 {
+		log.Printf("Worker %d processed batch in %s: %d processed, %d duplicates, %d invalid, %d failed",
+			id, batch.Duration, batch.Processed, batch.Duplicates, batch.Invalid, batch.Failed)
+	}
+
+	for i, delivery := range deliveries {
+		d.settle(delivery, batch.Results[i])
+	}
+}
+
+// settle acks processed and duplicate votes, rejects invalid ones since
+// redelivery would not help, and nacks failed ones so they are redelivered.
+func (d *Dispatcher) settle(delivery port.VoteDelivery, result port.VoteResult) {
+	switch result.Outcome {
+	case port.OutcomeProcessed, port.OutcomeDuplicate:
+		delivery.Ack()
+	case port.OutcomeInvalid:
+		delivery.Reject(fmt.Errorf("invalid vote: %w", result.Err))
+	case port.OutcomeFailed:
+		delivery.Nack(result.Err)
+	default:
+		delivery.Nack(fmt.Errorf("vote left in status %s", delivery.Vote().Status))
+	}
+}
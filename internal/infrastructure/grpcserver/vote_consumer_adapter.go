@@ -0,0 +1,275 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	votev1 "github.com/pdrhp/ms-voto-processor-go/api/vote/v1"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+type GRPCVoteConsumer struct {
+	votev1.UnimplementedVoteIngestServer
+
+	addr   string
+	server *grpc.Server
+	buffer int
+
+	// maxStreamVotes caps the votes one SubmitVotes stream may send, since
+	// they are all held until the client half-closes; zero or less is no cap
+	maxStreamVotes int
+
+	deliveries chan port.VoteDelivery
+
+	wg       sync.WaitGroup
+	inflight sync.WaitGroup
+}
+
+// grpcVoteDelivery reports the settled vote back to the RPC waiting on it.
+type grpcVoteDelivery struct {
+	vote    *entity.Vote
+	settled chan error
+}
+
+func newGRPCVoteDelivery(vote *entity.Vote) *grpcVoteDelivery {
+	return &grpcVoteDelivery{
+		vote:    vote,
+		settled: make(chan error, 1),
+	}
+}
+
+func (d *grpcVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *grpcVoteDelivery) Ack() {
+	d.settled <- nil
+}
+
+func (d *grpcVoteDelivery) Nack(err error) {
+	d.settled <- err
+}
+
+func (d *grpcVoteDelivery) Reject(err error) {
+	d.settled <- err
+}
+
+func NewGRPCVoteConsumer(addr string, buffer int, maxStreamVotes int) port.VoteConsumerPort {
+	c := &GRPCVoteConsumer{
+		addr:           addr,
+		server:         grpc.NewServer(),
+		buffer:         buffer,
+		maxStreamVotes: maxStreamVotes,
+	}
+
+	votev1.RegisterVoteIngestServer(c.server, c)
+
+	return c
+}
+
+func (c *GRPCVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", c.addr, err)
+	}
+
+	c.deliveries = make(chan port.VoteDelivery, c.buffer)
+
+	serveErr := make(chan error, 1)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(c.deliveries)
+
+		go func() {
+			serveErr <- c.server.Serve(listener)
+		}()
+
+		select {
+		case <-ctx.Done():
+		case err := <-serveErr:
+			if err != nil {
+				log.Printf("gRPC vote server stopped: %v", err)
+			}
+			return
+		}
+
+		// in-flight RPCs wait on their votes, which are still being settled
+		// by the dispatcher until the deliveries channel is closed
+		stopped := make(chan struct{})
+		go func() {
+			c.server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			log.Println("gRPC vote server did not drain in time, forcing stop")
+			c.server.Stop()
+		}
+
+		c.inflight.Wait()
+	}()
+
+	log.Printf("gRPC vote server listening on %s", c.addr)
+	return c.deliveries, nil
+}
+
+func (c *GRPCVoteConsumer) SubmitVote(ctx context.Context, req *votev1.SubmitVoteRequest) (*votev1.SubmitVoteResponse, error) {
+	c.inflight.Add(1)
+	defer c.inflight.Done()
+
+	delivery, ack, err := c.submit(ctx, 0, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &votev1.SubmitVoteResponse{Ack: c.await(ctx, delivery, ack)}, nil
+}
+
+func (c *GRPCVoteConsumer) SubmitVotes(stream votev1.VoteIngest_SubmitVotesServer) error {
+	c.inflight.Add(1)
+	defer c.inflight.Done()
+
+	ctx := stream.Context()
+
+	type pendingVote struct {
+		delivery *grpcVoteDelivery
+		ack      *votev1.VoteAck
+	}
+
+	var pending []pendingVote
+
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if c.maxStreamVotes > 0 && len(pending) >= c.maxStreamVotes {
+			return status.Errorf(codes.ResourceExhausted,
+				"stream exceeds %d votes, close it and open a new one", c.maxStreamVotes)
+		}
+
+		// a rejected vote keeps its error in the ack, the stream goes on
+		delivery, ack, _ := c.submit(ctx, index, req)
+		pending = append(pending, pendingVote{delivery: delivery, ack: ack})
+	}
+
+	acks := make([]*votev1.VoteAck, len(pending))
+	for i, p := range pending {
+		if p.delivery != nil {
+			acks[i] = c.await(ctx, p.delivery, p.ack)
+			continue
+		}
+		acks[i] = p.ack
+	}
+
+	return stream.SendAndClose(&votev1.SubmitVotesResponse{Acks: acks})
+}
+
+// submit validates the vote and hands it to the pipeline. When the vote never
+// enters the pipeline the error is returned and also recorded in the ack.
+func (c *GRPCVoteConsumer) submit(ctx context.Context, index int64, req *votev1.SubmitVoteRequest) (*grpcVoteDelivery, *votev1.VoteAck, error) {
+	ack := &votev1.VoteAck{Index: index}
+
+	if req.GetVote() == nil {
+		ack.Error = "vote is required"
+		return nil, ack, errors.New(ack.Error)
+	}
+
+	vote, err := toEntity(req.GetVote())
+	if err != nil {
+		ack.Error = err.Error()
+		return nil, ack, status.Error(codes.Internal, err.Error())
+	}
+	ack.VoteId = vote.ID
+
+	if err := vote.Validate(); err != nil {
+		ack.Error = err.Error()
+		return nil, ack, err
+	}
+
+	delivery := newGRPCVoteDelivery(vote)
+
+	select {
+	case c.deliveries <- delivery:
+		ack.Status = toProtoStatus(vote.Status)
+		return delivery, ack, nil
+	case <-ctx.Done():
+		ack.Error = ctx.Err().Error()
+		return nil, ack, ctx.Err()
+	}
+}
+
+func (c *GRPCVoteConsumer) await(ctx context.Context, delivery *grpcVoteDelivery, ack *votev1.VoteAck) *votev1.VoteAck {
+	select {
+	case err := <-delivery.settled:
+		ack.Status = toProtoStatus(delivery.vote.Status)
+		if err != nil {
+			ack.Error = err.Error()
+		}
+	case <-ctx.Done():
+		ack.Error = ctx.Err().Error()
+	}
+
+	return ack
+}
+
+func toEntity(message *votev1.Vote) (*entity.Vote, error) {
+	voteMessage := &models.VoteMessage{
+		ID:             message.GetId(),
+		ParticipanteID: int(message.GetParticipanteId()),
+		SessionID:      message.GetSessionId(),
+	}
+	if message.GetTimestamp() != nil {
+		voteMessage.Timestamp = message.GetTimestamp().AsTime()
+	}
+
+	return voteMessage.ToEntity()
+}
+
+func toProtoStatus(s entity.VoteStatus) votev1.VoteStatus {
+	switch s {
+	case entity.VoteStatusReceived:
+		return votev1.VoteStatus_VOTE_STATUS_RECEIVED
+	case entity.VoteStatusSent:
+		return votev1.VoteStatus_VOTE_STATUS_SENT
+	case entity.VoteStatusProcessing:
+		return votev1.VoteStatus_VOTE_STATUS_PROCESSING
+	case entity.VoteStatusProcessed:
+		return votev1.VoteStatus_VOTE_STATUS_PROCESSED
+	case entity.VoteStatusFailed:
+		return votev1.VoteStatus_VOTE_STATUS_FAILED
+	}
+	return votev1.VoteStatus_VOTE_STATUS_UNSPECIFIED
+}
+
+func (c *GRPCVoteConsumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
@@ -0,0 +1,119 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	votev1 "github.com/pdrhp/ms-voto-processor-go/api/vote/v1"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeVoteStream feeds requests to SubmitVotes and keeps its response.
+type fakeVoteStream struct {
+	grpc.ServerStream
+
+	ctx      context.Context
+	requests []*votev1.SubmitVoteRequest
+	response *votev1.SubmitVotesResponse
+}
+
+func (s *fakeVoteStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeVoteStream) Recv() (*votev1.SubmitVoteRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeVoteStream) SendAndClose(response *votev1.SubmitVotesResponse) error {
+	s.response = response
+	return nil
+}
+
+// newTestConsumer returns a consumer whose deliveries are all acked.
+func newTestConsumer(t *testing.T, maxStreamVotes int) *GRPCVoteConsumer {
+	t.Helper()
+
+	c := &GRPCVoteConsumer{
+		maxStreamVotes: maxStreamVotes,
+		deliveries:     make(chan port.VoteDelivery, 10),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for delivery := range c.deliveries {
+			delivery.Ack()
+		}
+	}()
+	t.Cleanup(func() {
+		close(c.deliveries)
+		<-done
+	})
+
+	return c
+}
+
+func voteRequests(n int) []*votev1.SubmitVoteRequest {
+	requests := make([]*votev1.SubmitVoteRequest, n)
+	for i := range requests {
+		requests[i] = &votev1.SubmitVoteRequest{Vote: &votev1.Vote{
+			Id:             fmt.Sprintf("vote-%03d", i+1),
+			ParticipanteId: 7,
+			SessionId:      "session-1",
+			Timestamp:      timestamppb.New(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		}}
+	}
+	return requests
+}
+
+func TestSubmitVotesStreamLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxStreamVotes int
+		votes          int
+		wantCode       codes.Code
+	}{
+		{name: "within limit", maxStreamVotes: 3, votes: 3, wantCode: codes.OK},
+		{name: "over limit", maxStreamVotes: 2, votes: 3, wantCode: codes.ResourceExhausted},
+		{name: "zero is unlimited", maxStreamVotes: 0, votes: 5, wantCode: codes.OK},
+		{name: "negative is unlimited", maxStreamVotes: -1, votes: 5, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(t, tt.maxStreamVotes)
+			stream := &fakeVoteStream{ctx: context.Background(), requests: voteRequests(tt.votes)}
+
+			err := c.SubmitVotes(stream)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("SubmitVotes() code = %v, want %v (error = %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			acks := stream.response.GetAcks()
+			if len(acks) != tt.votes {
+				t.Fatalf("got %d acks, want %d", len(acks), tt.votes)
+			}
+			for i, ack := range acks {
+				if ack.GetError() != "" || ack.GetIndex() != int64(i) {
+					t.Errorf("ack %d = %+v", i, ack)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+const maxRequestBodySize = 10 << 20
+
+// acceptedNote is sent with every 202: votes are only queued in memory, and
+// one that later fails to persist is logged and dropped, not reported back.
+const acceptedNote = "votes are queued for processing; acceptance does not guarantee they are stored"
+
+type HTTPVoteConsumer struct {
+	server *http.Server
+	buffer int
+
+	deliveries chan port.VoteDelivery
+
+	// handlers hold sendMu for reading while they send on deliveries, so the
+	// channel is only closed once none of them can still send on it
+	sendMu sync.RWMutex
+	closed bool
+
+	wg sync.WaitGroup
+}
+
+type httpVoteDelivery struct {
+	vote *entity.Vote
+}
+
+func (d *httpVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *httpVoteDelivery) Ack() {}
+
+// the request was already answered with 202, so failures can only be
+// logged; the response note tells clients acceptance is not a guarantee
+func (d *httpVoteDelivery) Nack(err error) {
+	log.Printf("Failed to process vote received over HTTP: ID=%s, error=%v", d.vote.ID, err)
+}
+
+func (d *httpVoteDelivery) Reject(err error) {
+	log.Printf("Vote received over HTTP rejected: ID=%s, error=%v", d.vote.ID, err)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type acceptedResponse struct {
+	Accepted int      `json:"accepted"`
+	IDs      []string `json:"ids"`
+	Note     string   `json:"note"`
+}
+
+func NewHTTPVoteConsumer(addr string, buffer int) port.VoteConsumerPort {
+	c := &HTTPVoteConsumer{
+		buffer: buffer,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /votes", c.handleVotes)
+
+	c.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return c
+}
+
+func (c *HTTPVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	listener, err := net.Listen("tcp", c.server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", c.server.Addr, err)
+	}
+
+	c.deliveries = make(chan port.VoteDelivery, c.buffer)
+
+	serveErr := make(chan error, 1)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.closeDeliveries()
+
+		go func() {
+			serveErr <- c.server.Serve(listener)
+		}()
+
+		select {
+		case <-ctx.Done():
+		case err := <-serveErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("HTTP vote server stopped: %v", err)
+			}
+			c.server.Close()
+			return
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := c.server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down HTTP vote server: %v", err)
+			// drop the connections still open so their handlers return
+			c.server.Close()
+		}
+	}()
+
+	log.Printf("HTTP vote server listening on %s", c.server.Addr)
+	return c.deliveries, nil
+}
+
+func (c *HTTPVoteConsumer) handleVotes(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("failed to read body: %v", err)})
+		return
+	}
+
+	messages, err := decodeVoteMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	votes := make([]*entity.Vote, len(messages))
+	for i, message := range messages {
+		if votes[i], err = message.ToEntity(); err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("vote %d: %v", i, err)})
+			return
+		}
+	}
+
+	c.sendMu.RLock()
+	defer c.sendMu.RUnlock()
+
+	if c.closed {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "vote server is shutting down"})
+		return
+	}
+
+	ids := make([]string, 0, len(votes))
+	for _, vote := range votes {
+		select {
+		case c.deliveries <- &httpVoteDelivery{vote: vote}:
+			ids = append(ids, vote.ID)
+		case <-r.Context().Done():
+			writeJSON(w, http.StatusServiceUnavailable, acceptedResponse{Accepted: len(ids), IDs: ids, Note: acceptedNote})
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusAccepted, acceptedResponse{Accepted: len(ids), IDs: ids, Note: acceptedNote})
+}
+
+// closeDeliveries waits for handlers still sending votes, which return once
+// their connection is closed, before closing the channel.
+func (c *HTTPVoteConsumer) closeDeliveries() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.closed = true
+	close(c.deliveries)
+}
+
+func decodeVoteMessages(body []byte) ([]*models.VoteMessage, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("request body is empty")
+	}
+
+	var raw []json.RawMessage
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("vote array is empty")
+		}
+	} else {
+		raw = []json.RawMessage{trimmed}
+	}
+
+	messages := make([]*models.VoteMessage, len(raw))
+	for i, data := range raw {
+		message := &models.VoteMessage{}
+		if err := message.FromJSON(data); err != nil {
+			return nil, fmt.Errorf("vote %d: invalid JSON: %w", i, err)
+		}
+		if err := message.Validate(); err != nil {
+			return nil, fmt.Errorf("vote %d: %w", i, err)
+		}
+		messages[i] = message
+	}
+
+	return messages, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write HTTP response: %v", err)
+	}
+}
+
+func (c *HTTPVoteConsumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
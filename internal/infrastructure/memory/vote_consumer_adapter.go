@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+var _ port.VoteConsumerPort = (*InMemoryVoteConsumer)(nil)
+
+type Outcome string
+
+const (
+	OutcomeAck    Outcome = "ack"
+	OutcomeNack   Outcome = "nack"
+	OutcomeReject Outcome = "reject"
+)
+
+type Settlement struct {
+	Vote    *entity.Vote
+	Outcome Outcome
+	Err     error
+}
+
+type ConsumerOptions struct {
+	Buffer int
+	// Latency is waited before each delivery is handed out.
+	Latency time.Duration
+	// DuplicateEvery delivers every nth published vote twice, as a broker
+	// redelivering an unacked message would. Zero disables duplicates.
+	DuplicateEvery int
+	// ConsumeErr makes Consume fail, as an unreachable broker would.
+	ConsumeErr error
+}
+
+// InMemoryVoteConsumer delivers published votes and records how each
+// delivery was settled, so tests can drive the pipeline without a broker.
+type InMemoryVoteConsumer struct {
+	opts  ConsumerOptions
+	queue chan *entity.Vote
+
+	mu          sync.Mutex
+	published   int
+	settlements []Settlement
+	settled     chan struct{}
+
+	finishOnce sync.Once
+	wg         sync.WaitGroup
+}
+
+type inMemoryVoteDelivery struct {
+	consumer *InMemoryVoteConsumer
+	vote     *entity.Vote
+}
+
+func (d *inMemoryVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *inMemoryVoteDelivery) Ack() {
+	d.consumer.record(d.vote, OutcomeAck, nil)
+}
+
+func (d *inMemoryVoteDelivery) Nack(err error) {
+	d.consumer.record(d.vote, OutcomeNack, err)
+}
+
+func (d *inMemoryVoteDelivery) Reject(err error) {
+	d.consumer.record(d.vote, OutcomeReject, err)
+}
+
+func NewInMemoryVoteConsumer(opts ConsumerOptions) *InMemoryVoteConsumer {
+	if opts.Buffer < 1 {
+		opts.Buffer = 1024
+	}
+
+	return &InMemoryVoteConsumer{
+		opts:    opts,
+		queue:   make(chan *entity.Vote, opts.Buffer),
+		settled: make(chan struct{}, 1),
+	}
+}
+
+// Publish queues votes for delivery; it blocks once the buffer is full.
+func (c *InMemoryVoteConsumer) Publish(votes ...*entity.Vote) {
+	for _, vote := range votes {
+		c.queue <- vote
+	}
+}
+
+// Finish closes the queue; the delivery channel closes once it is drained,
+// which lets the dispatcher flush and stop as it would at shutdown.
+func (c *InMemoryVoteConsumer) Finish() {
+	c.finishOnce.Do(func() {
+		close(c.queue)
+	})
+}
+
+func (c *InMemoryVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	if c.opts.ConsumeErr != nil {
+		return nil, c.opts.ConsumeErr
+	}
+
+	deliveries := make(chan port.VoteDelivery, c.opts.Buffer)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(deliveries)
+
+		for {
+			select {
+			case vote, ok := <-c.queue:
+				if !ok {
+					return
+				}
+				for _, delivered := range c.expand(vote) {
+					if !c.deliver(ctx, deliveries, delivered) {
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return deliveries, nil
+}
+
+func (c *InMemoryVoteConsumer) expand(vote *entity.Vote) []*entity.Vote {
+	c.mu.Lock()
+	c.published++
+	duplicate := c.opts.DuplicateEvery > 0 && c.published%c.opts.DuplicateEvery == 0
+	c.mu.Unlock()
+
+	if !duplicate {
+		return []*entity.Vote{vote}
+	}
+
+	redelivered := *vote
+	return []*entity.Vote{vote, &redelivered}
+}
+
+func (c *InMemoryVoteConsumer) deliver(ctx context.Context, deliveries chan<- port.VoteDelivery, vote *entity.Vote) bool {
+	if c.opts.Latency > 0 {
+		select {
+		case <-time.After(c.opts.Latency):
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	select {
+	case deliveries <- &inMemoryVoteDelivery{consumer: c, vote: vote}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *InMemoryVoteConsumer) record(vote *entity.Vote, outcome Outcome, err error) {
+	c.mu.Lock()
+	c.settlements = append(c.settlements, Settlement{Vote: vote, Outcome: outcome, Err: err})
+	c.mu.Unlock()
+
+	select {
+	case c.settled <- struct{}{}:
+	default:
+	}
+}
+
+func (c *InMemoryVoteConsumer) Settlements() []Settlement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Settlement(nil), c.settlements...)
+}
+
+func (c *InMemoryVoteConsumer) Settled(outcome Outcome) []Settlement {
+	var matched []Settlement
+	for _, settlement := range c.Settlements() {
+		if settlement.Outcome == outcome {
+			matched = append(matched, settlement)
+		}
+	}
+	return matched
+}
+
+// WaitForSettlements blocks until at least n deliveries have been settled.
+func (c *InMemoryVoteConsumer) WaitForSettlements(n int, timeout time.Duration) error {
+	deadline := time.After(timeout)
+
+	for {
+		if len(c.Settlements()) >= n {
+			return nil
+		}
+
+		select {
+		case <-c.settled:
+		case <-deadline:
+			return errors.New("timed out waiting for settlements")
+		}
+	}
+}
+
+func (c *InMemoryVoteConsumer) Close() error {
+	c.wg.Wait()
+	return nil
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+var _ port.VoteRepositoryPort = (*InMemoryVoteRepository)(nil)
+
+// InMemoryVoteRepository keeps saved votes in a map with the same upsert and
+// insert-only semantics as the Postgres repository. Failures and latency
+// can be injected to exercise the error paths of the pipeline.
+type InMemoryVoteRepository struct {
+	mu sync.Mutex
+
+	votes      map[string]entity.Vote
+	duplicates int
+	batches    []int
+
+	insertOnly bool
+
+	latency   time.Duration
+	failNext  int
+	failErr   error
+	failVotes func(vote *entity.Vote) error
+}
+
+func NewInMemoryVoteRepository() *InMemoryVoteRepository {
+	return &InMemoryVoteRepository{
+		votes: make(map[string]entity.Vote),
+	}
+}
+
+// FailNext makes the next n calls to Save or BulkSave return err as a
+// transient failure, like a dropped connection.
+func (r *InMemoryVoteRepository) FailNext(n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failNext = n
+	r.failErr = err
+}
+
+// FailVotes rejects any call that includes a vote for which fn returns an
+// error; like a constraint violation, it fails the whole batch with a row
+// error.
+func (r *InMemoryVoteRepository) FailVotes(fn func(vote *entity.Vote) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failVotes = fn
+}
+
+// SetInsertOnly makes saves skip votes whose ID is already stored and report
+// them as duplicates instead of overwriting them.
+func (r *InMemoryVoteRepository) SetInsertOnly(insertOnly bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insertOnly = insertOnly
+}
+
+func (r *InMemoryVoteRepository) SetLatency(latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latency = latency
+}
+
+func (r *InMemoryVoteRepository) Save(ctx context.Context, vote *entity.Vote) error {
+	if vote == nil {
+		return fmt.Errorf("vote cannot be nil")
+	}
+
+	duplicates, err := r.BulkSave(ctx, []*entity.Vote{vote})
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return port.ErrDuplicateVote
+	}
+	return nil
+}
+
+func (r *InMemoryVoteRepository) BulkSave(ctx context.Context, votes []*entity.Vote) ([]string, error) {
+	if len(votes) == 0 {
+		return nil, nil
+	}
+
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, len(votes))
+
+	if r.failNext > 0 {
+		r.failNext--
+		return nil, &port.TransientError{Err: r.failErr}
+	}
+
+	for i, vote := range votes {
+		if vote == nil {
+			return nil, fmt.Errorf("vote at index %d cannot be nil", i)
+		}
+		if err := vote.Validate(); err != nil {
+			return nil, &port.RowError{Err: fmt.Errorf("invalid vote at index %d: %w", i, err)}
+		}
+		if r.failVotes != nil {
+			if err := r.failVotes(vote); err != nil {
+				return nil, &port.RowError{Err: fmt.Errorf("failed to bulk save votes: %w", err)}
+			}
+		}
+	}
+
+	var duplicates []string
+	for _, vote := range votes {
+		if _, exists := r.votes[vote.ID]; exists {
+			r.duplicates++
+			if r.insertOnly {
+				duplicates = append(duplicates, vote.ID)
+				continue
+			}
+		}
+		r.votes[vote.ID] = *vote
+	}
+
+	return duplicates, nil
+}
+
+func (r *InMemoryVoteRepository) wait(ctx context.Context) error {
+	r.mu.Lock()
+	latency := r.latency
+	r.mu.Unlock()
+
+	if latency <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(latency):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get returns a copy of the vote as it was saved.
+func (r *InMemoryVoteRepository) Get(id string) (*entity.Vote, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vote, ok := r.votes[id]
+	if !ok {
+		return nil, false
+	}
+	return &vote, true
+}
+
+func (r *InMemoryVoteRepository) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.votes)
+}
+
+// Duplicates counts saves of a vote already stored, whether they overwrote
+// it or, in insert-only mode, were skipped.
+func (r *InMemoryVoteRepository) Duplicates() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.duplicates
+}
+
+// Batches returns the size of every Save and BulkSave call, in call order.
+func (r *InMemoryVoteRepository) Batches() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int(nil), r.batches...)
+}
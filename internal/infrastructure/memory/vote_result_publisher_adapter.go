@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+var _ port.VoteResultPublisherPort = (*InMemoryVoteResultPublisher)(nil)
+
+// InMemoryVoteResultPublisher records a snapshot of every published vote.
+type InMemoryVoteResultPublisher struct {
+	mu      sync.Mutex
+	results []entity.Vote
+	failErr error
+}
+
+func NewInMemoryVoteResultPublisher() *InMemoryVoteResultPublisher {
+	return &InMemoryVoteResultPublisher{}
+}
+
+// Fail makes every later publish return err; nil restores publishing.
+func (p *InMemoryVoteResultPublisher) Fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failErr = err
+}
+
+func (p *InMemoryVoteResultPublisher) PublishResults(ctx context.Context, votes []*entity.Vote) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failErr != nil {
+		return p.failErr
+	}
+
+	for _, vote := range votes {
+		p.results = append(p.results, *vote)
+	}
+	return nil
+}
+
+func (p *InMemoryVoteResultPublisher) Results() []entity.Vote {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]entity.Vote(nil), p.results...)
+}
+
+func (p *InMemoryVoteResultPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package messaging
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
+)
+
+const (
+	ContentTypeAvro = "application/avro"
+
+	confluentMagicByte  = 0
+	confluentHeaderSize = 5
+)
+
+// AvroVoteDecoder decodes Confluent wire format payloads: a zero magic byte,
+// a big-endian schema ID and the Avro binary body.
+type AvroVoteDecoder struct {
+	registry schemaregistry.SchemaRegistry
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func NewAvroVoteDecoder(registry schemaregistry.SchemaRegistry, timeout time.Duration) *AvroVoteDecoder {
+	return &AvroVoteDecoder{
+		registry: registry,
+		timeout:  timeout,
+		schemas:  make(map[int]avro.Schema),
+	}
+}
+
+func (d *AvroVoteDecoder) Decode(payload []byte) (*models.VoteMessage, error) {
+	if len(payload) < confluentHeaderSize {
+		return nil, fmt.Errorf("avro payload too short: %d bytes", len(payload))
+	}
+	if payload[0] != confluentMagicByte {
+		return nil, fmt.Errorf("unknown avro magic byte %d", payload[0])
+	}
+
+	id := int(binary.BigEndian.Uint32(payload[1:confluentHeaderSize]))
+
+	schema, err := d.schema(id)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &models.VoteMessage{}
+	if err := message.FromAvro(schema, payload[confluentHeaderSize:]); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (d *AvroVoteDecoder) schema(id int) (avro.Schema, error) {
+	d.mu.RLock()
+	schema, ok := d.schemas[id]
+	d.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	definition, err := d.registry.GetSchema(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve avro schema: %w", err)
+	}
+
+	// every registry schema gets its own cache so evolved versions of the
+	// same record name do not clash
+	schema, err = avro.ParseWithCache(definition, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema %d: %w", id, err)
+	}
+
+	d.mu.Lock()
+	d.schemas[id] = schema
+	d.mu.Unlock()
+
+	return schema, nil
+}
@@ -0,0 +1,191 @@
+package messaging
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
+)
+
+const testSchemaDir = "../../../api/vote/v1/schemas"
+
+type testAvroVote struct {
+	ID             *string   `avro:"id"`
+	ParticipanteID int64     `avro:"participanteId"`
+	SessionID      string    `avro:"sessionId"`
+	Timestamp      time.Time `avro:"timestamp"`
+}
+
+// stubSchemaRegistry fails the first failures lookups with err and then
+// serves schemas from the local schema directory.
+type stubSchemaRegistry struct {
+	err      error
+	failures int
+	calls    int
+}
+
+func (r *stubSchemaRegistry) GetSchema(ctx context.Context, id int) (string, error) {
+	r.calls++
+	if r.calls <= r.failures {
+		return "", r.err
+	}
+	return schemaregistry.NewFileSchemaRegistry(testSchemaDir).GetSchema(ctx, id)
+}
+
+// confluentPayload encodes vote with schema 1 in the Confluent wire format.
+func confluentPayload(t *testing.T, schemaID uint32, vote testAvroVote) []byte {
+	t.Helper()
+
+	definition, err := schemaregistry.NewFileSchemaRegistry(testSchemaDir).GetSchema(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetSchema() error = %v", err)
+	}
+	schema, err := avro.Parse(definition)
+	if err != nil {
+		t.Fatalf("avro.Parse() error = %v", err)
+	}
+	body, err := avro.Marshal(schema, vote)
+	if err != nil {
+		t.Fatalf("avro.Marshal() error = %v", err)
+	}
+
+	payload := make([]byte, confluentHeaderSize, confluentHeaderSize+len(body))
+	binary.BigEndian.PutUint32(payload[1:], schemaID)
+	return append(payload, body...)
+}
+
+func TestAvroVoteDecoderDecodesConfluentWireFormat(t *testing.T) {
+	id := "vote-1"
+
+	tests := []struct {
+		name   string
+		vote   testAvroVote
+		wantID string
+	}{
+		{
+			name:   "with id",
+			vote:   testAvroVote{ID: &id, ParticipanteID: 7, SessionID: "session-1", Timestamp: testVoteTime},
+			wantID: "vote-1",
+		},
+		{
+			name:   "null id",
+			vote:   testAvroVote{ParticipanteID: 7, SessionID: "session-1", Timestamp: testVoteTime},
+			wantID: "",
+		},
+	}
+
+	decoder := NewAvroVoteDecoder(schemaregistry.NewFileSchemaRegistry(testSchemaDir), time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := decoder.Decode(confluentPayload(t, 1, tt.vote))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if message.ID != tt.wantID || message.ParticipanteID != 7 || message.SessionID != "session-1" {
+				t.Errorf("message = %+v", message)
+			}
+			if !message.Timestamp.Equal(testVoteTime) {
+				t.Errorf("Timestamp = %v, want %v", message.Timestamp, testVoteTime)
+			}
+		})
+	}
+}
+
+func TestAvroVoteDecoderRejectsMalformedInput(t *testing.T) {
+	valid := confluentPayload(t, 1, testAvroVote{ParticipanteID: 7, SessionID: "session-1", Timestamp: testVoteTime})
+
+	tests := []struct {
+		name    string
+		payload []byte
+		wantErr string
+	}{
+		{
+			name:    "empty payload",
+			payload: nil,
+			wantErr: "avro payload too short: 0 bytes",
+		},
+		{
+			name:    "header cut short",
+			payload: []byte{0, 0, 0, 1},
+			wantErr: "avro payload too short: 4 bytes",
+		},
+		{
+			name:    "unknown magic byte",
+			payload: append([]byte{1}, valid[1:]...),
+			wantErr: "unknown avro magic byte 1",
+		},
+		{
+			name:    "unknown schema id",
+			payload: confluentPayload(t, 42, testAvroVote{SessionID: "session-1", Timestamp: testVoteTime}),
+			wantErr: "schema not found",
+		},
+		{
+			name:    "truncated body",
+			payload: valid[:len(valid)-3],
+			wantErr: "invalid avro body",
+		},
+	}
+
+	decoder := NewAvroVoteDecoder(schemaregistry.NewFileSchemaRegistry(testSchemaDir), time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := decoder.Decode(tt.payload)
+			if err == nil {
+				t.Fatalf("Decode() = %+v, want error", message)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if schemaregistry.IsUnavailable(err) {
+				t.Errorf("Decode() error = %v reported as registry unavailable", err)
+			}
+		})
+	}
+}
+
+func TestAvroVoteDecoderRegistryUnavailable(t *testing.T) {
+	outage := &schemaregistry.UnavailableError{Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name            string
+		err             error
+		wantUnavailable bool
+	}{
+		{name: "outage is retryable", err: outage, wantUnavailable: true},
+		{name: "other registry errors are not", err: errors.New("schema 1 has unsupported type JSON")},
+	}
+
+	payload := confluentPayload(t, 1, testAvroVote{ParticipanteID: 7, SessionID: "session-1", Timestamp: testVoteTime})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &stubSchemaRegistry{err: tt.err, failures: 1}
+			decoders := NewDecoderRegistry()
+			decoders.RegisterContentType(ContentTypeAvro, NewAvroVoteDecoder(registry, time.Second))
+			headers := map[string]string{HeaderContentType: ContentTypeAvro}
+
+			_, err := decoders.Decode(payload, headers)
+			if err == nil {
+				t.Fatal("Decode() with failing registry succeeded")
+			}
+			if got := schemaregistry.IsUnavailable(err); got != tt.wantUnavailable {
+				t.Errorf("IsUnavailable(%v) = %v, want %v", err, got, tt.wantUnavailable)
+			}
+
+			// a failed lookup is not cached, so the next decode succeeds
+			if _, err := decoders.Decode(payload, headers); err != nil {
+				t.Fatalf("Decode() after registry recovered error = %v", err)
+			}
+			if _, err := decoders.Decode(payload, headers); err != nil {
+				t.Fatalf("Decode() with cached schema error = %v", err)
+			}
+			if registry.calls != 2 {
+				t.Errorf("registry calls = %d, want 2", registry.calls)
+			}
+		})
+	}
+}
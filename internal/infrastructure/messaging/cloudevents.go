@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+const (
+	ContentTypeCloudEventsJSON = "application/cloudevents+json"
+
+	cloudEventSpecVersion = "specversion"
+	cloudEventID          = "id"
+	cloudEventSource      = "source"
+	cloudEventTime        = "time"
+)
+
+// cloudEventHeader reads a binary mode attribute; the Kafka binding prefixes
+// attributes with "ce_" and the HTTP binding with "ce-".
+func cloudEventHeader(headers map[string]string, attribute string) string {
+	if value, ok := headers["ce_"+attribute]; ok {
+		return value
+	}
+	return headers["ce-"+attribute]
+}
+
+func isBinaryCloudEvent(headers map[string]string) bool {
+	return cloudEventHeader(headers, cloudEventSpecVersion) != ""
+}
+
+func isStructuredCloudEvent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.EqualFold(mediaType, ContentTypeCloudEventsJSON)
+}
+
+func (r *DecoderRegistry) decodeBinaryCloudEvent(payload []byte, contentType string, headers map[string]string) (*models.VoteMessage, error) {
+	event := &models.CloudEvent{
+		SpecVersion: cloudEventHeader(headers, cloudEventSpecVersion),
+		ID:          cloudEventHeader(headers, cloudEventID),
+		Source:      cloudEventHeader(headers, cloudEventSource),
+	}
+
+	if value := cloudEventHeader(headers, cloudEventTime); value != "" {
+		eventTime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudevent time %q: %w", value, err)
+		}
+		event.Time = &eventTime
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cloudevent: %w", err)
+	}
+
+	message, err := r.decodeContent(payload, contentType, headers[HeaderSchemaVersion])
+	if err != nil {
+		return nil, err
+	}
+
+	message.SetEventOrigin(event)
+	return message, nil
+}
+
+func (r *DecoderRegistry) decodeStructuredCloudEvent(payload []byte, headers map[string]string) (*models.VoteMessage, error) {
+	event := &models.CloudEvent{}
+	if err := event.FromJSON(payload); err != nil {
+		return nil, fmt.Errorf("failed to decode cloudevent: %w", err)
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cloudevent: %w", err)
+	}
+
+	data, err := event.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("invalid cloudevent data: %w", err)
+	}
+
+	contentType := event.DataContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	message, err := r.decodeContent(data, contentType, headers[HeaderSchemaVersion])
+	if err != nil {
+		return nil, err
+	}
+
+	message.SetEventOrigin(event)
+	return message, nil
+}
@@ -0,0 +1,188 @@
+package messaging
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoderRegistryDecodesBinaryCloudEvent(t *testing.T) {
+	eventTime := time.Date(2024, 1, 1, 12, 0, 1, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		payload  string
+		headers  map[string]string
+		wantTime *time.Time
+	}{
+		{
+			name:    "kafka binding",
+			payload: testVotePayload,
+			headers: map[string]string{
+				"ce_specversion": "1.0",
+				"ce_id":          "event-1",
+				"ce_source":      "/votos/app",
+				"ce_time":        "2024-01-01T12:00:01Z",
+			},
+			wantTime: &eventTime,
+		},
+		{
+			name:    "http binding without time",
+			payload: testVotePayload,
+			headers: map[string]string{
+				"ce-specversion":  "1.0",
+				"ce-id":           "event-1",
+				"ce-source":       "/votos/app",
+				HeaderContentType: ContentTypeJSON,
+			},
+		},
+		{
+			name:    "enveloped data",
+			payload: `{"schemaVersion":"1","data":` + testVotePayload + `}`,
+			headers: map[string]string{
+				"ce_specversion": "1.0",
+				"ce_id":          "event-1",
+				"ce_source":      "/votos/app",
+			},
+		},
+	}
+
+	registry := NewDecoderRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), tt.headers)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkVoteMessage(t, message)
+
+			if message.EventID != "event-1" || message.EventSource != "/votos/app" {
+				t.Errorf("event origin = %s, %s", message.EventID, message.EventSource)
+			}
+			switch {
+			case tt.wantTime == nil && message.EventTime != nil:
+				t.Errorf("EventTime = %v, want nil", message.EventTime)
+			case tt.wantTime != nil && (message.EventTime == nil || !message.EventTime.Equal(*tt.wantTime)):
+				t.Errorf("EventTime = %v, want %v", message.EventTime, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestDecoderRegistryDecodesStructuredCloudEvent(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(testVotePayload))
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "json data",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app","type":"vote.cast","data":` + testVotePayload + `}`,
+		},
+		{
+			name:    "base64 data",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app","type":"vote.cast","data_base64":"` + encoded + `"}`,
+		},
+		{
+			name:    "explicit data content type",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app","datacontenttype":"application/json","data":` + testVotePayload + `}`,
+		},
+	}
+
+	registry := NewDecoderRegistry()
+	headers := map[string]string{HeaderContentType: ContentTypeCloudEventsJSON + "; charset=utf-8"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), headers)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkVoteMessage(t, message)
+
+			if message.EventID != "event-1" || message.EventSource != "/votos/app" {
+				t.Errorf("event origin = %s, %s", message.EventID, message.EventSource)
+			}
+		})
+	}
+}
+
+func TestDecoderRegistryRejectsMalformedCloudEvent(t *testing.T) {
+	structured := map[string]string{HeaderContentType: ContentTypeCloudEventsJSON}
+
+	tests := []struct {
+		name    string
+		payload string
+		headers map[string]string
+		wantErr string
+	}{
+		{
+			name:    "binary without id",
+			payload: testVotePayload,
+			headers: map[string]string{"ce_specversion": "1.0", "ce_source": "/votos/app"},
+			wantErr: "invalid cloudevent: id is required",
+		},
+		{
+			name:    "binary without source",
+			payload: testVotePayload,
+			headers: map[string]string{"ce_specversion": "1.0", "ce_id": "event-1"},
+			wantErr: "invalid cloudevent: source is required",
+		},
+		{
+			name:    "binary with malformed time",
+			payload: testVotePayload,
+			headers: map[string]string{"ce_specversion": "1.0", "ce_id": "event-1", "ce_source": "/votos/app", "ce_time": "yesterday"},
+			wantErr: `invalid cloudevent time "yesterday"`,
+		},
+		{
+			name:    "binary with malformed data",
+			payload: `{"id":`,
+			headers: map[string]string{"ce_specversion": "1.0", "ce_id": "event-1", "ce_source": "/votos/app"},
+			wantErr: "unexpected end of JSON input",
+		},
+		{
+			name:    "structured malformed json",
+			payload: `{"specversion":`,
+			headers: structured,
+			wantErr: "failed to decode cloudevent",
+		},
+		{
+			name:    "structured without specversion",
+			payload: `{"id":"event-1","source":"/votos/app","data":` + testVotePayload + `}`,
+			headers: structured,
+			wantErr: "invalid cloudevent: specversion is required",
+		},
+		{
+			name:    "structured without data",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app"}`,
+			headers: structured,
+			wantErr: "invalid cloudevent data: cloudevent event-1 has no data",
+		},
+		{
+			name:    "structured with malformed base64",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app","data_base64":"%%%"}`,
+			headers: structured,
+			wantErr: "invalid cloudevent data",
+		},
+		{
+			name:    "structured with unsupported data content type",
+			payload: `{"specversion":"1.0","id":"event-1","source":"/votos/app","datacontenttype":"text/xml","data":"<vote/>"}`,
+			headers: structured,
+			wantErr: `unsupported content type "text/xml"`,
+		},
+	}
+
+	registry := NewDecoderRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), tt.headers)
+			if err == nil {
+				t.Fatalf("Decode() = %+v, want error", message)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
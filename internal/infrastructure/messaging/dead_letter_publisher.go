@@ -0,0 +1,69 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderDeadLetterTopic     = "dlq-source-topic"
+	HeaderDeadLetterPartition = "dlq-source-partition"
+	HeaderDeadLetterOffset    = "dlq-source-offset"
+	HeaderDeadLetterReason    = "dlq-reason"
+	HeaderDeadLetterTimestamp = "dlq-timestamp"
+)
+
+type KafkaDeadLetterPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaDeadLetterPublisher(brokers []string, topic string) *KafkaDeadLetterPublisher {
+	return &KafkaDeadLetterPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *KafkaDeadLetterPublisher) Publish(ctx context.Context, msg kafka.Message, reason error) error {
+	record := deadLetterRecord(msg, reason, time.Now())
+
+	if err := p.writer.WriteMessages(ctx, record); err != nil {
+		return fmt.Errorf("failed to publish to dead letter topic: %w", err)
+	}
+
+	return nil
+}
+
+// deadLetterRecord keeps the original key, value and headers of msg and adds
+// where it was consumed from and why it was given up on.
+func deadLetterRecord(msg kafka.Message, reason error, now time.Time) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDeadLetterReason, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderDeadLetterTimestamp, Value: []byte(now.UTC().Format(time.RFC3339Nano))},
+	)
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+func (p *KafkaDeadLetterPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,52 @@
+package messaging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestDeadLetterRecordHeaders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+	msg := kafka.Message{
+		Topic:     "votos.retry.5m",
+		Partition: 3,
+		Offset:    120,
+		Key:       []byte("session-1"),
+		Value:     []byte("payload"),
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte("application/json")},
+			{Key: HeaderRetryAttempt, Value: []byte("3")},
+		},
+	}
+
+	record := deadLetterRecord(msg, errors.New("invalid vote"), now)
+
+	if record.Topic != "" {
+		t.Errorf("topic = %s, want it left to the writer", record.Topic)
+	}
+	if string(record.Key) != "session-1" || string(record.Value) != "payload" {
+		t.Errorf("key, value = %q, %q", record.Key, record.Value)
+	}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "content-type", want: "application/json"},
+		{header: HeaderRetryAttempt, want: "3"},
+		{header: HeaderDeadLetterTopic, want: "votos.retry.5m"},
+		{header: HeaderDeadLetterPartition, want: "3"},
+		{header: HeaderDeadLetterOffset, want: "120"},
+		{header: HeaderDeadLetterReason, want: "invalid vote"},
+		{header: HeaderDeadLetterTimestamp, want: "2024-01-01T15:00:00Z"},
+	}
+	for _, tt := range tests {
+		values := headerValues(record, tt.header)
+		if len(values) != 1 || values[0] != tt.want {
+			t.Errorf("header %s = %v, want [%s]", tt.header, values, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+const (
+	HeaderContentType   = "content-type"
+	HeaderSchemaVersion = "schema-version"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+
+	SchemaVersionV1 = "1"
+)
+
+type VoteDecoder interface {
+	Decode(payload []byte) (*models.VoteMessage, error)
+}
+
+type VoteDecoderFunc func(payload []byte) (*models.VoteMessage, error)
+
+func (f VoteDecoderFunc) Decode(payload []byte) (*models.VoteMessage, error) {
+	return f(payload)
+}
+
+// DecoderRegistry resolves the decoder for a message from its headers.
+// CloudEvents, in binary or structured mode, are unwrapped first.
+// Non-JSON content types map straight to a decoder; JSON payloads may be
+// wrapped in a VoteEnvelope and are dispatched by schema version, taken from
+// the schema-version header, the content-type version parameter or the
+// envelope itself, in that order. Unversioned JSON is treated as v1.
+type DecoderRegistry struct {
+	versions           map[string]VoteDecoder
+	contentTypes       map[string]VoteDecoder
+	defaultContentType string
+}
+
+func NewDecoderRegistry() *DecoderRegistry {
+	registry := &DecoderRegistry{
+		versions:           make(map[string]VoteDecoder),
+		contentTypes:       make(map[string]VoteDecoder),
+		defaultContentType: ContentTypeJSON,
+	}
+
+	registry.RegisterVersion(SchemaVersionV1, VoteDecoderFunc(decodeJSONV1))
+	registry.RegisterContentType(ContentTypeProtobuf, VoteDecoderFunc(decodeProtobufV1))
+	registry.RegisterContentType("application/protobuf", VoteDecoderFunc(decodeProtobufV1))
+
+	return registry
+}
+
+func (r *DecoderRegistry) RegisterVersion(version string, decoder VoteDecoder) {
+	r.versions[normalizeSchemaVersion(version)] = decoder
+}
+
+func (r *DecoderRegistry) RegisterContentType(contentType string, decoder VoteDecoder) {
+	r.contentTypes[strings.ToLower(contentType)] = decoder
+}
+
+// SetDefaultContentType sets the format assumed for messages without a
+// content-type header, e.g. Confluent Avro producers that never set one.
+func (r *DecoderRegistry) SetDefaultContentType(contentType string) {
+	r.defaultContentType = contentType
+}
+
+func (r *DecoderRegistry) Decode(payload []byte, headers map[string]string) (*models.VoteMessage, error) {
+	contentType := headers[HeaderContentType]
+	if contentType == "" {
+		contentType = r.defaultContentType
+	}
+
+	if isBinaryCloudEvent(headers) {
+		return r.decodeBinaryCloudEvent(payload, contentType, headers)
+	}
+
+	if isStructuredCloudEvent(contentType) {
+		return r.decodeStructuredCloudEvent(payload, headers)
+	}
+
+	return r.decodeContent(payload, contentType, headers[HeaderSchemaVersion])
+}
+
+func (r *DecoderRegistry) decodeContent(payload []byte, contentType, version string) (*models.VoteMessage, error) {
+	mediaType := ContentTypeJSON
+
+	if contentType != "" {
+		parsed, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+		}
+		mediaType = parsed
+		if version == "" {
+			version = params["version"]
+		}
+	}
+
+	if mediaType != ContentTypeJSON {
+		decoder, ok := r.contentTypes[mediaType]
+		if !ok {
+			return nil, fmt.Errorf("unsupported content type %q", mediaType)
+		}
+		return decoder.Decode(payload)
+	}
+
+	data := payload
+
+	envelope := &models.VoteEnvelope{}
+	if err := envelope.FromJSON(payload); err == nil && envelope.IsEnveloped() {
+		data = envelope.Data
+		if version == "" {
+			version = envelope.SchemaVersion
+		}
+	}
+
+	if version == "" {
+		version = SchemaVersionV1
+	}
+
+	decoder, ok := r.versions[normalizeSchemaVersion(version)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema version %q", version)
+	}
+
+	return decoder.Decode(data)
+}
+
+func decodeJSONV1(payload []byte) (*models.VoteMessage, error) {
+	message := &models.VoteMessage{}
+
+	if err := message.FromJSON(payload); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func decodeProtobufV1(payload []byte) (*models.VoteMessage, error) {
+	message := &models.VoteMessage{}
+
+	if err := message.FromProtobuf(payload); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func normalizeSchemaVersion(version string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+}
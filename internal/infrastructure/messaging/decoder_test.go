@@ -0,0 +1,261 @@
+package messaging
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	votev1 "github.com/pdrhp/ms-voto-processor-go/api/vote/v1"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var testVoteTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// checkVoteMessage compares message with the vote encoded by testVotePayload.
+func checkVoteMessage(t *testing.T, message *models.VoteMessage) {
+	t.Helper()
+
+	if message.ID != "vote-1" || message.ParticipanteID != 7 || message.SessionID != "session-1" {
+		t.Errorf("message = %+v", message)
+	}
+	if !message.Timestamp.Equal(testVoteTime) {
+		t.Errorf("Timestamp = %v, want %v", message.Timestamp, testVoteTime)
+	}
+}
+
+func TestDecoderRegistryDecodesJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		headers map[string]string
+	}{
+		{
+			name:    "plain json without headers",
+			payload: testVotePayload,
+		},
+		{
+			name:    "plain json with content type",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderContentType: "application/json; charset=utf-8"},
+		},
+		{
+			name:    "envelope carries its version",
+			payload: `{"schemaVersion":"1","data":` + testVotePayload + `}`,
+		},
+		{
+			name:    "envelope version with v prefix",
+			payload: `{"schemaVersion":"V1","data":` + testVotePayload + `}`,
+		},
+		{
+			name:    "schema version header",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderSchemaVersion: "v1"},
+		},
+		{
+			name:    "content type version parameter",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderContentType: "application/json; version=1"},
+		},
+	}
+
+	registry := NewDecoderRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), tt.headers)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkVoteMessage(t, message)
+		})
+	}
+}
+
+func TestDecoderRegistryDispatchesBySchemaVersion(t *testing.T) {
+	registry := NewDecoderRegistry()
+	registry.RegisterVersion("v2", VoteDecoderFunc(func(payload []byte) (*models.VoteMessage, error) {
+		return &models.VoteMessage{ID: "from-v2"}, nil
+	}))
+
+	tests := []struct {
+		name    string
+		payload string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "envelope version",
+			payload: `{"schemaVersion":"2","data":{}}`,
+			want:    "from-v2",
+		},
+		{
+			name:    "header wins over envelope",
+			payload: `{"schemaVersion":"2","data":` + testVotePayload + `}`,
+			headers: map[string]string{HeaderSchemaVersion: "1"},
+			want:    "vote-1",
+		},
+		{
+			name:    "content type parameter wins over envelope",
+			payload: `{"schemaVersion":"1","data":{}}`,
+			headers: map[string]string{HeaderContentType: "application/json; version=2"},
+			want:    "from-v2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), tt.headers)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if message.ID != tt.want {
+				t.Errorf("ID = %s, want %s", message.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoderRegistryRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		headers map[string]string
+		wantErr string
+	}{
+		{
+			name:    "malformed json",
+			payload: `{"id":`,
+			wantErr: "unexpected end of JSON input",
+		},
+		{
+			name:    "unknown schema version header",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderSchemaVersion: "9"},
+			wantErr: `unsupported schema version "9"`,
+		},
+		{
+			name:    "unknown envelope version",
+			payload: `{"schemaVersion":"3","data":` + testVotePayload + `}`,
+			wantErr: `unsupported schema version "3"`,
+		},
+		{
+			name:    "unparseable content type",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderContentType: "application/json; ="},
+			wantErr: "invalid content type",
+		},
+		{
+			name:    "unsupported content type",
+			payload: testVotePayload,
+			headers: map[string]string{HeaderContentType: "text/xml"},
+			wantErr: `unsupported content type "text/xml"`,
+		},
+	}
+
+	registry := NewDecoderRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode([]byte(tt.payload), tt.headers)
+			if err == nil {
+				t.Fatalf("Decode() = %+v, want error", message)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecoderRegistryDefaultContentType(t *testing.T) {
+	registry := NewDecoderRegistry()
+	registry.RegisterContentType("application/x-test", VoteDecoderFunc(func(payload []byte) (*models.VoteMessage, error) {
+		return &models.VoteMessage{ID: string(payload)}, nil
+	}))
+	registry.SetDefaultContentType("application/x-test")
+
+	message, err := registry.Decode([]byte("raw"), nil)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if message.ID != "raw" {
+		t.Errorf("ID = %s, want raw", message.ID)
+	}
+
+	message, err = registry.Decode([]byte(testVotePayload), map[string]string{HeaderContentType: ContentTypeJSON})
+	if err != nil {
+		t.Fatalf("Decode() with content type error = %v", err)
+	}
+	checkVoteMessage(t, message)
+}
+
+func TestDecoderRegistryDecodesProtobuf(t *testing.T) {
+	payload, err := proto.Marshal(&votev1.Vote{
+		Id:             "vote-1",
+		ParticipanteId: 7,
+		SessionId:      "session-1",
+		Timestamp:      timestamppb.New(testVoteTime),
+	})
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "x-protobuf", contentType: ContentTypeProtobuf},
+		{name: "protobuf", contentType: "application/protobuf"},
+		{name: "case and parameters ignored", contentType: "Application/X-Protobuf; messageType=vote.v1.Vote"},
+	}
+
+	registry := NewDecoderRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := registry.Decode(payload, map[string]string{HeaderContentType: tt.contentType})
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkVoteMessage(t, message)
+		})
+	}
+}
+
+func TestDecoderRegistryRejectsMalformedProtobuf(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "truncated length", payload: []byte{0x1a, 0x10, 's'}},
+		{name: "invalid wire type", payload: []byte{0x0f}},
+		{name: "json sent as protobuf", payload: []byte(testVotePayload)},
+	}
+
+	registry := NewDecoderRegistry()
+	headers := map[string]string{HeaderContentType: ContentTypeProtobuf}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message, err := registry.Decode(tt.payload, headers); err == nil {
+				t.Fatalf("Decode() = %+v, want error", message)
+			}
+		})
+	}
+}
+
+func TestDecoderRegistryProtobufWithoutTimestamp(t *testing.T) {
+	payload, err := proto.Marshal(&votev1.Vote{ParticipanteId: 7, SessionId: "session-1"})
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	message, err := NewDecoderRegistry().Decode(payload, map[string]string{HeaderContentType: ContentTypeProtobuf})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !message.Timestamp.IsZero() {
+		t.Errorf("Timestamp = %v, want zero", message.Timestamp)
+	}
+	if err := message.Validate(); err == nil {
+		t.Error("Validate() accepted a vote without timestamp")
+	}
+}
@@ -0,0 +1,24 @@
+package messaging
+
+import (
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func headerValue(msg kafka.Message, key string) string {
+	for i := len(msg.Headers) - 1; i >= 0; i-- {
+		if msg.Headers[i].Key == key {
+			return string(msg.Headers[i].Value)
+		}
+	}
+	return ""
+}
+
+func headerMap(msg kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[strings.ToLower(header.Key)] = string(header.Value)
+	}
+	return headers
+}
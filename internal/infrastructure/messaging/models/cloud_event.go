@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+func (e *CloudEvent) FromJSON(data []byte) error {
+	return json.Unmarshal(data, e)
+}
+
+func (e *CloudEvent) Validate() error {
+	if e.SpecVersion == "" {
+		return fmt.Errorf("specversion is required")
+	}
+	if e.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if e.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	return nil
+}
+
+func (e *CloudEvent) Payload() ([]byte, error) {
+	if e.DataBase64 != "" {
+		return base64.StdEncoding.DecodeString(e.DataBase64)
+	}
+	if len(e.Data) == 0 {
+		return nil, fmt.Errorf("cloudevent %s has no data", e.ID)
+	}
+	return e.Data, nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+type VoteEnvelope struct {
+	SchemaVersion string          `json:"schemaVersion"`
+	Data          json.RawMessage `json:"data"`
+}
+
+func (e *VoteEnvelope) FromJSON(data []byte) error {
+	return json.Unmarshal(data, e)
+}
+
+func (e *VoteEnvelope) IsEnveloped() bool {
+	return e.SchemaVersion != "" && len(e.Data) > 0
+}
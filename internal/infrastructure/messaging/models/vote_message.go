@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hamba/avro/v2"
+	votev1 "github.com/pdrhp/ms-voto-processor-go/api/vote/v1"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/util"
+	"google.golang.org/protobuf/proto"
 )
 
 type VoteMessage struct {
@@ -14,27 +17,119 @@ type VoteMessage struct {
 	ParticipanteID int      `json:"participanteId"`
 	SessionID     string    `json:"sessionId"`
 	Timestamp     time.Time `json:"timestamp"`
+
+	EventID     string     `json:"-"`
+	EventSource string     `json:"-"`
+	EventTime   *time.Time `json:"-"`
+
+	// Origin identifies where the message was read from, e.g. a Kafka
+	// topic/partition/offset, and seeds the ID when the producer sent none.
+	Origin string `json:"-"`
+}
+
+// voteIDNamespace is the UUIDv5 namespace of IDs derived in deriveID.
+var voteIDNamespace = [16]byte{
+	0x6b, 0x2f, 0x1d, 0x84, 0x3c, 0x5e, 0x4a, 0x97,
+	0x8e, 0x0b, 0x52, 0xd6, 0x19, 0xa3, 0xf4, 0x70,
 }
 
-func (v *VoteMessage) ToEntity() *entity.Vote {
+type avroVoteRecord struct {
+	ID             *string   `avro:"id"`
+	ParticipanteID int64     `avro:"participanteId"`
+	SessionID      string    `avro:"sessionId"`
+	Timestamp      time.Time `avro:"timestamp"`
+}
+
+func (v *VoteMessage) ToEntity() (*entity.Vote, error) {
 	id := v.ID
 	if id == "" {
-		id = util.GenerateUUID()
+		derived, err := v.deriveID()
+		if err != nil {
+			return nil, err
+		}
+		id = derived
 	}
 
-	return entity.NewVoteFromData(
+	vote := entity.NewVoteFromData(
 		id,
 		v.ParticipanteID,
 		v.SessionID,
 		v.Timestamp,
 		entity.VoteStatusReceived,
 	)
+	vote.EventID = v.EventID
+	vote.EventSource = v.EventSource
+	vote.EventTime = v.EventTime
+
+	return vote, nil
+}
+
+// deriveID makes redelivery of an ID-less message idempotent: the same
+// origin and content always produce the same UUIDv5. Without an origin
+// there is nothing stable to derive from, so a fresh time-ordered ID is used.
+func (v *VoteMessage) deriveID() (string, error) {
+	if v.Origin == "" {
+		return util.NewID()
+	}
+
+	name := fmt.Sprintf("%s|%d|%s|%s",
+		v.Origin,
+		v.ParticipanteID,
+		v.SessionID,
+		v.Timestamp.UTC().Format(time.RFC3339Nano),
+	)
+
+	return util.GenerateUUIDv5(voteIDNamespace, name), nil
+}
+
+func (v *VoteMessage) SetEventOrigin(event *CloudEvent) {
+	v.EventID = event.ID
+	v.EventSource = event.Source
+	v.EventTime = event.Time
 }
 
 func (v *VoteMessage) FromJSON(data []byte) error {
 	return json.Unmarshal(data, v)
 }
 
+func (v *VoteMessage) FromProtobuf(data []byte) error {
+	message := &votev1.Vote{}
+	if err := proto.Unmarshal(data, message); err != nil {
+		return err
+	}
+
+	v.ID = message.GetId()
+	v.ParticipanteID = int(message.GetParticipanteId())
+	v.SessionID = message.GetSessionId()
+	v.Timestamp = time.Time{}
+	if message.GetTimestamp() != nil {
+		v.Timestamp = message.GetTimestamp().AsTime()
+	}
+
+	return nil
+}
+
+func (v *VoteMessage) FromAvro(schema avro.Schema, data []byte) error {
+	// avro.Unmarshal reports running out of input as success, so a truncated
+	// body would decode into zero fields; reading directly surfaces the EOF
+	record := &avroVoteRecord{}
+	reader := avro.NewReader(nil, 0).Reset(data)
+	reader.ReadVal(schema, record)
+	if reader.Error != nil {
+		return fmt.Errorf("invalid avro body: %w", reader.Error)
+	}
+
+	v.ID = ""
+	if record.ID != nil {
+		v.ID = *record.ID
+	}
+	v.ParticipanteID = int(record.ParticipanteID)
+	v.SessionID = record.SessionID
+	v.Timestamp = record.Timestamp
+
+	return nil
+}
+
 func (v *VoteMessage) Validate() error {
 	if v.ParticipanteID <= 0 {
 		return fmt.Errorf("participanteId must be greater than zero")
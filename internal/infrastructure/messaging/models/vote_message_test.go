@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+)
+
+func toEntity(t *testing.T, message *VoteMessage) *entity.Vote {
+	t.Helper()
+
+	vote, err := message.ToEntity()
+	if err != nil {
+		t.Fatalf("ToEntity() error = %v", err)
+	}
+	return vote
+}
+
+func newTestMessage(origin string) *VoteMessage {
+	return &VoteMessage{
+		ParticipanteID: 7,
+		SessionID:      "session-1",
+		Timestamp:      time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Origin:         origin,
+	}
+}
+
+func TestToEntityDerivesStableIDFromOrigin(t *testing.T) {
+	first := toEntity(t, newTestMessage("kafka:votos/3/1042"))
+	redelivered := toEntity(t, newTestMessage("kafka:votos/3/1042"))
+
+	if first.ID != redelivered.ID {
+		t.Fatalf("redelivered ID = %s, want %s", redelivered.ID, first.ID)
+	}
+	if first.ID[14] != '5' {
+		t.Errorf("ID %s is not a version 5 UUID", first.ID)
+	}
+
+	other := toEntity(t, newTestMessage("kafka:votos/3/1043"))
+	if other.ID == first.ID {
+		t.Errorf("messages at different offsets share ID %s", first.ID)
+	}
+}
+
+func TestToEntityKeepsProducerID(t *testing.T) {
+	message := newTestMessage("kafka:votos/3/1042")
+	message.ID = "producer-id"
+
+	if got := toEntity(t, message).ID; got != "producer-id" {
+		t.Errorf("ID = %s, want producer-id", got)
+	}
+}
+
+func TestToEntityWithoutOriginGeneratesFreshID(t *testing.T) {
+	if toEntity(t, newTestMessage("")).ID == toEntity(t, newTestMessage("")).ID {
+		t.Error("messages without origin got the same ID")
+	}
+}
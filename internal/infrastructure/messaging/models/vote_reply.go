@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type VoteReply struct {
+	CorrelationID string     `json:"correlationId"`
+	VoteID        string     `json:"voteId,omitempty"`
+	Status        string     `json:"status"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	RepliedAt     time.Time  `json:"repliedAt"`
+}
+
+func (r *VoteReply) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+)
+
+const (
+	EventTypeVoteProcessed = "VoteProcessed"
+	EventTypeVoteFailed    = "VoteFailed"
+)
+
+type VoteResultEvent struct {
+	EventType       string     `json:"eventType"`
+	VoteID          string     `json:"voteId"`
+	ParticipanteID  int        `json:"participanteId"`
+	SessionID       string     `json:"sessionId"`
+	Status          string     `json:"status"`
+	Timestamp       time.Time  `json:"timestamp"`
+	ProcessedAt     *time.Time `json:"processedAt,omitempty"`
+	ProcessingError *string    `json:"processingError,omitempty"`
+	OccurredAt      time.Time  `json:"occurredAt"`
+}
+
+// NewVoteResultEvent returns nil for votes that have not reached a final
+// status, since there is no outcome to report yet.
+func NewVoteResultEvent(vote *entity.Vote) *VoteResultEvent {
+	var eventType string
+	switch vote.Status {
+	case entity.VoteStatusProcessed:
+		eventType = EventTypeVoteProcessed
+	case entity.VoteStatusFailed:
+		eventType = EventTypeVoteFailed
+	default:
+		return nil
+	}
+
+	return &VoteResultEvent{
+		EventType:       eventType,
+		VoteID:          vote.ID,
+		ParticipanteID:  vote.ParticipantID,
+		SessionID:       vote.SessionID,
+		Status:          string(vote.Status),
+		Timestamp:       vote.Timestamp,
+		ProcessedAt:     vote.ProcessedAt,
+		ProcessingError: vote.ProcessingError,
+		OccurredAt:      time.Now().UTC(),
+	}
+}
+
+func (e *VoteResultEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -0,0 +1,108 @@
+package messaging
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+type trackedOffset struct {
+	msg   kafka.Message
+	acked bool
+}
+
+type partitionOffsets struct {
+	pending     []*trackedOffset
+	committable *kafka.Message
+}
+
+// offsetTracker keeps fetched messages in fetch order per partition and only
+// exposes for commit the highest offset whose predecessors were all acked.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[topicPartition]*partitionOffsets),
+	}
+}
+
+func (t *offsetTracker) track(msg kafka.Message) *trackedOffset {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{}
+		t.partitions[key] = p
+	}
+
+	// an offset going backwards means the partition was reassigned or
+	// rewound, so whatever was pending will be fetched again
+	if n := len(p.pending); n > 0 && msg.Offset <= p.pending[n-1].msg.Offset {
+		p.pending = nil
+		p.committable = nil
+	}
+
+	tracked := &trackedOffset{msg: msg}
+	p.pending = append(p.pending, tracked)
+	return tracked
+}
+
+func (t *offsetTracker) ack(tracked *trackedOffset) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked.acked = true
+
+	key := topicPartition{topic: tracked.msg.Topic, partition: tracked.msg.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		return
+	}
+
+	for len(p.pending) > 0 && p.pending[0].acked {
+		msg := p.pending[0].msg
+		p.committable = &msg
+		p.pending = p.pending[1:]
+	}
+}
+
+func (t *offsetTracker) committable() []kafka.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var msgs []kafka.Message
+	for _, p := range t.partitions {
+		if p.committable != nil {
+			msgs = append(msgs, *p.committable)
+			p.committable = nil
+		}
+	}
+	return msgs
+}
+
+func (t *offsetTracker) restore(msgs []kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, msg := range msgs {
+		key := topicPartition{topic: msg.Topic, partition: msg.Partition}
+		p, ok := t.partitions[key]
+		if !ok {
+			continue
+		}
+		if p.committable == nil || p.committable.Offset < msg.Offset {
+			restored := msg
+			p.committable = &restored
+		}
+	}
+}
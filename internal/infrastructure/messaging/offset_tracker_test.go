@@ -0,0 +1,157 @@
+package messaging
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type trackStep struct {
+	partition int
+	offset    int64
+}
+
+func committedOffsets(tracker *offsetTracker) map[int]int64 {
+	offsets := make(map[int]int64)
+	for _, msg := range tracker.committable() {
+		offsets[msg.Partition] = msg.Offset
+	}
+	return offsets
+}
+
+func TestOffsetTrackerCommitsHighestContiguousAck(t *testing.T) {
+	tests := []struct {
+		name    string
+		fetched []trackStep
+		acks    []int
+		want    map[int]int64
+	}{
+		{
+			name:    "acks in order",
+			fetched: []trackStep{{offset: 10}, {offset: 11}, {offset: 12}},
+			acks:    []int{0, 1, 2},
+			want:    map[int]int64{0: 12},
+		},
+		{
+			name:    "gap holds later acks back",
+			fetched: []trackStep{{offset: 10}, {offset: 11}, {offset: 12}},
+			acks:    []int{1, 2},
+			want:    map[int]int64{},
+		},
+		{
+			name:    "out of order acks commit once the gap closes",
+			fetched: []trackStep{{offset: 10}, {offset: 11}, {offset: 12}, {offset: 13}},
+			acks:    []int{2, 1, 0},
+			want:    map[int]int64{0: 12},
+		},
+		{
+			name: "partitions advance independently",
+			fetched: []trackStep{
+				{partition: 0, offset: 5},
+				{partition: 1, offset: 40},
+				{partition: 0, offset: 6},
+				{partition: 1, offset: 41},
+			},
+			acks: []int{2, 1, 3},
+			want: map[int]int64{1: 41},
+		},
+		{
+			name: "offset going backwards drops pending acks",
+			fetched: []trackStep{
+				{offset: 10},
+				{offset: 11},
+				{offset: 10},
+			},
+			acks: []int{0, 1},
+			want: map[int]int64{},
+		},
+		{
+			name: "rewound partition commits from its new position",
+			fetched: []trackStep{
+				{offset: 10},
+				{offset: 11},
+				{offset: 10},
+				{offset: 11},
+			},
+			acks: []int{2, 3},
+			want: map[int]int64{0: 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+
+			tracked := make([]*trackedOffset, 0, len(tt.fetched))
+			for _, step := range tt.fetched {
+				tracked = append(tracked, tracker.track(kafka.Message{
+					Topic: "votos", Partition: step.partition, Offset: step.offset,
+				}))
+			}
+			for _, index := range tt.acks {
+				tracker.ack(tracked[index])
+			}
+
+			got := committedOffsets(tracker)
+			if len(got) != len(tt.want) {
+				t.Fatalf("committable = %v, want %v", got, tt.want)
+			}
+			for partition, offset := range tt.want {
+				if got[partition] != offset {
+					t.Errorf("partition %d offset = %d, want %d", partition, got[partition], offset)
+				}
+			}
+		})
+	}
+}
+
+func TestOffsetTrackerCommittableIsTakenOnce(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.ack(tracker.track(kafka.Message{Topic: "votos", Offset: 1}))
+
+	if got := tracker.committable(); len(got) != 1 {
+		t.Fatalf("first committable = %v, want one message", got)
+	}
+	if got := tracker.committable(); len(got) != 0 {
+		t.Fatalf("second committable = %v, want none", got)
+	}
+}
+
+func TestOffsetTrackerRestoreKeepsHigherOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		restored int64
+		acked    []int64
+		want     int64
+	}{
+		{name: "nothing acked since", restored: 3, want: 3},
+		{name: "newer ack wins", restored: 3, acked: []int64{4, 5}, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+			tracker.ack(tracker.track(kafka.Message{Topic: "votos", Offset: tt.restored}))
+			failed := tracker.committable()
+
+			for _, offset := range tt.acked {
+				tracker.ack(tracker.track(kafka.Message{Topic: "votos", Offset: offset}))
+			}
+			tracker.restore(failed)
+
+			got := committedOffsets(tracker)
+			if got[0] != tt.want {
+				t.Errorf("offset = %d, want %d", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetTrackerRestoreSkipsUnknownPartition(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.restore([]kafka.Message{{Topic: "votos", Partition: 3, Offset: 9}})
+
+	if got := tracker.committable(); len(got) != 0 {
+		t.Fatalf("committable = %v, want none", got)
+	}
+}
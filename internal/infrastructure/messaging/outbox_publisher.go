@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
+	"github.com/segmentio/kafka-go"
+)
+
+const HeaderOutboxID = "outbox-id"
+
+// KafkaOutboxPublisher publishes outbox rows keyed by session ID, so every
+// event of a session goes to the same partition in relay order.
+type KafkaOutboxPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaOutboxPublisher(brokers []string, topic string) *KafkaOutboxPublisher {
+	return &KafkaOutboxPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *KafkaOutboxPublisher) PublishOutbox(ctx context.Context, events []*models.VoteOutboxModel) error {
+	records := make([]kafka.Message, len(events))
+
+	for i, event := range events {
+		records[i] = kafka.Message{
+			Key:   []byte(event.SessionID),
+			Value: event.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventType, Value: []byte(event.EventType)},
+				{Key: HeaderContentType, Value: []byte(ContentTypeJSON)},
+				{Key: HeaderOutboxID, Value: []byte(strconv.FormatInt(event.ID, 10))},
+			},
+		}
+	}
+
+	if err := p.writer.WriteMessages(ctx, records...); err != nil {
+		return fmt.Errorf("failed to publish outbox events: %w", err)
+	}
+
+	return nil
+}
+
+func (p *KafkaOutboxPublisher) Close() error {
+	return p.writer.Close()
+}
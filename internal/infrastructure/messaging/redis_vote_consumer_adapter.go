@@ -0,0 +1,411 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RedisFieldPayload = "payload"
+	RedisFieldReason  = "dlq-reason"
+	RedisFieldSource  = "dlq-source-id"
+)
+
+// RedisVoteConsumer reads a Redis stream through a consumer group. Entries
+// stay pending until acked; a nacked entry, or one held by a consumer that
+// died, is reclaimed with XCLAIM once idle for the visibility timeout, and
+// dead lettered once it was delivered more than maxDeliveries times.
+type RedisVoteConsumer struct {
+	client        redis.UniversalClient
+	stream        string
+	group         string
+	consumer      string
+	deadLetter    string
+	batch         int
+	block         time.Duration
+	visibility    time.Duration
+	maxDeliveries int
+
+	decoders *DecoderRegistry
+
+	acksMu sync.Mutex
+	acks   []string
+
+	// entries handed to the pipeline and not yet acked, nacked or dead
+	// lettered; reclaim skips them however long their batch runs
+	inflightMu sync.Mutex
+	inflight   map[string]struct{}
+
+	wg sync.WaitGroup
+}
+
+type redisVoteDelivery struct {
+	consumer *RedisVoteConsumer
+	vote     *entity.Vote
+	message  redis.XMessage
+}
+
+func (d *redisVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *redisVoteDelivery) Ack() {
+	d.consumer.acksMu.Lock()
+	d.consumer.acks = append(d.consumer.acks, d.message.ID)
+	d.consumer.acksMu.Unlock()
+}
+
+// the entry stays pending and is reclaimed after the visibility timeout
+func (d *redisVoteDelivery) Nack(err error) {
+	log.Printf("Vote not acknowledged, stream entry left pending: stream=%s id=%s error=%v",
+		d.consumer.stream, d.message.ID, err)
+	d.consumer.settled(d.message.ID)
+}
+
+func (d *redisVoteDelivery) Reject(err error) {
+	d.consumer.deadLetterEntry(context.Background(), d.message, err)
+}
+
+func NewRedisVoteConsumer(client redis.UniversalClient, cfg *config.RedisConfig, batchSize int, decoders *DecoderRegistry) port.VoteConsumerPort {
+	return &RedisVoteConsumer{
+		client:        client,
+		stream:        cfg.Stream,
+		group:         cfg.ConsumerGroup,
+		consumer:      cfg.ConsumerName,
+		deadLetter:    cfg.DeadLetterStream,
+		batch:         batchSize,
+		block:         cfg.BlockTimeout,
+		visibility:    cfg.VisibilityTimeout,
+		maxDeliveries: cfg.MaxDeliveries,
+		decoders:      decoders,
+		inflight:      make(map[string]struct{}),
+	}
+}
+
+func (c *RedisVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group %s on stream %s: %w", c.group, c.stream, err)
+	}
+
+	deliveries := make(chan port.VoteDelivery, c.batch)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(deliveries)
+		c.readLoop(ctx, deliveries)
+	}()
+
+	log.Printf("Redis consumer %s reading stream %s as group %s", c.consumer, c.stream, c.group)
+	return deliveries, nil
+}
+
+func (c *RedisVoteConsumer) readLoop(ctx context.Context, deliveries chan<- port.VoteDelivery) {
+	claimCursor := "-"
+	lastClaim := time.Time{}
+
+	for {
+		c.flushAcks(ctx)
+
+		var messages []redis.XMessage
+
+		if time.Since(lastClaim) >= c.visibility/2 {
+			claimed, next, err := c.reclaim(ctx, claimCursor)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to reclaim pending stream entries: %v", err)
+			}
+			messages = append(messages, claimed...)
+			claimCursor = next
+			if next == "-" {
+				lastClaim = time.Now()
+			}
+		}
+
+		fresh, err := c.readNew(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to read stream %s: %v", c.stream, err)
+			select {
+			case <-time.After(c.block):
+			case <-ctx.Done():
+				return
+			}
+		}
+		messages = append(messages, fresh...)
+
+		for _, message := range messages {
+			vote, err := c.decodeHolding(ctx, message)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.deadLetterEntry(ctx, message, err)
+				continue
+			}
+
+			delivery := &redisVoteDelivery{
+				consumer: c,
+				vote:     vote,
+				message:  message,
+			}
+
+			c.inflightMu.Lock()
+			c.inflight[message.ID] = struct{}{}
+			c.inflightMu.Unlock()
+
+			select {
+			case deliveries <- delivery:
+			case <-ctx.Done():
+				c.settled(message.ID)
+				return
+			}
+		}
+	}
+}
+
+// reclaim takes over entries idle for longer than the visibility timeout,
+// walking the pending list from cursor; it returns "-" once the scan wrapped.
+// Entries this consumer still has in flight are left alone, and entries
+// that would exceed maxDeliveries are dead lettered instead of redelivered.
+// XPENDING and XCLAIM are used rather than XAUTOCLAIM because XAUTOCLAIM
+// claims every idle entry, including a slow batch still in flight here,
+// and each such claim would count as a delivery towards maxDeliveries.
+func (c *RedisVoteConsumer) reclaim(ctx context.Context, cursor string) ([]redis.XMessage, string, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.stream,
+		Group:  c.group,
+		Idle:   c.visibility,
+		Start:  cursor,
+		End:    "+",
+		Count:  int64(c.batch),
+	}).Result()
+	if err != nil {
+		return nil, "-", err
+	}
+
+	next := "-"
+	if len(pending) == c.batch {
+		next = nextStreamID(pending[len(pending)-1].ID)
+	}
+
+	// XCLAIM counts as one more delivery
+	deliveries := make(map[string]int64, len(pending))
+	ids := make([]string, 0, len(pending))
+	for _, entry := range pending {
+		if entry.Consumer == c.consumer && c.isInflight(entry.ID) {
+			continue
+		}
+		deliveries[entry.ID] = entry.RetryCount + 1
+		ids = append(ids, entry.ID)
+	}
+
+	if len(ids) == 0 {
+		return nil, next, nil
+	}
+
+	claimed, err := c.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   c.stream,
+		Group:    c.group,
+		Consumer: c.consumer,
+		MinIdle:  c.visibility,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, next, err
+	}
+
+	messages := make([]redis.XMessage, 0, len(claimed))
+	for _, message := range claimed {
+		if c.maxDeliveries > 0 && deliveries[message.ID] > int64(c.maxDeliveries) {
+			c.deadLetterEntry(ctx, message, fmt.Errorf("stream entry not acknowledged after %d deliveries", c.maxDeliveries))
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	if len(messages) > 0 {
+		log.Printf("Reclaimed %d pending stream entries from %s", len(messages), c.stream)
+	}
+
+	return messages, next, nil
+}
+
+// nextStreamID returns the smallest entry ID after id, so a range starting
+// there excludes id itself.
+func nextStreamID(id string) string {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return id
+	}
+
+	sequence, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return id
+	}
+
+	return fmt.Sprintf("%s-%d", ms, sequence+1)
+}
+
+func (c *RedisVoteConsumer) isInflight(id string) bool {
+	c.inflightMu.Lock()
+	defer c.inflightMu.Unlock()
+
+	_, ok := c.inflight[id]
+	return ok
+}
+
+func (c *RedisVoteConsumer) settled(ids ...string) {
+	c.inflightMu.Lock()
+	for _, id := range ids {
+		delete(c.inflight, id)
+	}
+	c.inflightMu.Unlock()
+}
+
+func (c *RedisVoteConsumer) readNew(ctx context.Context) ([]redis.XMessage, error) {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.consumer,
+		Streams:  []string{c.stream, ">"},
+		Count:    int64(c.batch),
+		Block:    c.block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages, nil
+}
+
+// decodeHolding decodes message, holding the read loop while the schema
+// registry is unavailable, as the Kafka consumer holds its partition. The
+// entry is not left pending for reclaim meanwhile, since every reclaim
+// would count as one more delivery although the entry was never processed.
+func (c *RedisVoteConsumer) decodeHolding(ctx context.Context, message redis.XMessage) (*entity.Vote, error) {
+	backoff := fetchBackoffMin
+
+	for {
+		vote, err := c.decode(message)
+		if err == nil || !schemaregistry.IsUnavailable(err) {
+			return vote, err
+		}
+
+		log.Printf("Schema registry unavailable, holding stream: stream=%s id=%s retry_in=%s error=%v",
+			c.stream, message.ID, backoff, err)
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return nil, err
+		}
+		backoff = min(backoff*2, fetchBackoffMax)
+
+		// votes already delivered keep settling while the stream is held
+		c.flushAcks(ctx)
+	}
+}
+
+func (c *RedisVoteConsumer) decode(message redis.XMessage) (*entity.Vote, error) {
+	payload, ok := message.Values[RedisFieldPayload].(string)
+	if !ok {
+		return nil, fmt.Errorf("stream entry has no %s field", RedisFieldPayload)
+	}
+
+	headers := make(map[string]string, len(message.Values))
+	for key, value := range message.Values {
+		if key == RedisFieldPayload {
+			continue
+		}
+		if text, ok := value.(string); ok {
+			headers[strings.ToLower(key)] = text
+		}
+	}
+
+	voteMessage, err := c.decoders.Decode([]byte(payload), headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vote message: %w", err)
+	}
+
+	if err := voteMessage.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid vote message: %w", err)
+	}
+
+	// entry IDs survive XCLAIM, so a reclaimed entry keeps its vote ID
+	voteMessage.Origin = fmt.Sprintf("redis:%s/%s", c.stream, message.ID)
+
+	return voteMessage.ToEntity()
+}
+
+// deadLetterEntry copies the entry to the dead letter stream and acks it;
+// if the copy fails the entry stays pending and will be reclaimed.
+func (c *RedisVoteConsumer) deadLetterEntry(ctx context.Context, message redis.XMessage, reason error) {
+	defer c.settled(message.ID)
+
+	log.Printf("Sending stream entry to dead letter stream: stream=%s id=%s reason=%v", c.stream, message.ID, reason)
+
+	values := make(map[string]interface{}, len(message.Values)+2)
+	for key, value := range message.Values {
+		values[key] = value
+	}
+	values[RedisFieldReason] = reason.Error()
+	values[RedisFieldSource] = message.ID
+
+	if err := c.client.XAdd(ctx, &redis.XAddArgs{Stream: c.deadLetter, Values: values}).Err(); err != nil {
+		log.Printf("Failed to dead letter stream entry %s: %v", message.ID, err)
+		return
+	}
+
+	if err := c.client.XAck(ctx, c.stream, c.group, message.ID).Err(); err != nil {
+		log.Printf("Failed to ack dead lettered stream entry %s: %v", message.ID, err)
+	}
+}
+
+func (c *RedisVoteConsumer) flushAcks(ctx context.Context) {
+	c.acksMu.Lock()
+	acks := c.acks
+	c.acks = nil
+	c.acksMu.Unlock()
+
+	if len(acks) == 0 {
+		return
+	}
+
+	if err := c.client.XAck(ctx, c.stream, c.group, acks...).Err(); err != nil {
+		log.Printf("Failed to ack stream entries: %v", err)
+		c.acksMu.Lock()
+		c.acks = append(c.acks, acks...)
+		c.acksMu.Unlock()
+		return
+	}
+
+	c.settled(acks...)
+}
+
+func (c *RedisVoteConsumer) Close() error {
+	c.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.flushAcks(ctx)
+
+	return c.client.Close()
+}
@@ -0,0 +1,285 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testStream     = "votos"
+	testGroup      = "vote-processor"
+	testDeadLetter = "votos.dlq"
+	testVisibility = 200 * time.Millisecond
+)
+
+type redisHarness struct {
+	server     *miniredis.Miniredis
+	client     *redis.Client
+	now        time.Time
+	deliveries <-chan port.VoteDelivery
+}
+
+// startRedisConsumer runs a consumer against miniredis, whose clock is
+// frozen so tests move entries past the visibility timeout explicitly.
+func startRedisConsumer(t *testing.T, maxDeliveries int) *redisHarness {
+	t.Helper()
+	return startRedisConsumerWith(t, maxDeliveries, NewDecoderRegistry())
+}
+
+func startRedisConsumerWith(t *testing.T, maxDeliveries int, decoders *DecoderRegistry) *redisHarness {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	h := &redisHarness{
+		server: server,
+		client: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		now:    time.Now(),
+	}
+	server.SetTime(h.now)
+
+	cfg := &config.RedisConfig{
+		Stream:            testStream,
+		ConsumerGroup:     testGroup,
+		ConsumerName:      "worker-1",
+		DeadLetterStream:  testDeadLetter,
+		BlockTimeout:      10 * time.Millisecond,
+		VisibilityTimeout: testVisibility,
+		MaxDeliveries:     maxDeliveries,
+	}
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	consumer := NewRedisVoteConsumer(client, cfg, 10, decoders)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deliveries, err := consumer.Consume(ctx)
+	if err != nil {
+		cancel()
+		t.Fatalf("Consume() error = %v", err)
+	}
+	h.deliveries = deliveries
+
+	t.Cleanup(func() {
+		cancel()
+		for range deliveries {
+		}
+		consumer.Close()
+		h.client.Close()
+	})
+
+	return h
+}
+
+func (h *redisHarness) add(t *testing.T, payload string) string {
+	t.Helper()
+	return h.addWith(t, map[string]interface{}{RedisFieldPayload: payload})
+}
+
+func (h *redisHarness) addWith(t *testing.T, values map[string]interface{}) string {
+	t.Helper()
+
+	id, err := h.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: testStream,
+		Values: values,
+	}).Result()
+	if err != nil {
+		t.Fatalf("XAdd() error = %v", err)
+	}
+	return id
+}
+
+// expire moves the frozen clock past the visibility timeout.
+func (h *redisHarness) expire() {
+	h.now = h.now.Add(testVisibility + time.Millisecond)
+	h.server.SetTime(h.now)
+}
+
+func (h *redisHarness) receive(t *testing.T) port.VoteDelivery {
+	t.Helper()
+
+	select {
+	case delivery := <-h.deliveries:
+		return delivery
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery received")
+		return nil
+	}
+}
+
+func (h *redisHarness) expectNoDelivery(t *testing.T, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case delivery := <-h.deliveries:
+		t.Fatalf("unexpected delivery of vote %s", delivery.Vote().ID)
+	case <-time.After(wait):
+	}
+}
+
+func (h *redisHarness) pending(t *testing.T) int64 {
+	t.Helper()
+
+	summary, err := h.client.XPending(context.Background(), testStream, testGroup).Result()
+	if err != nil {
+		t.Fatalf("XPending() error = %v", err)
+	}
+	return summary.Count
+}
+
+func (h *redisHarness) deliveryCount(t *testing.T, id string) int64 {
+	t.Helper()
+
+	entries, err := h.client.XPendingExt(context.Background(), &redis.XPendingExtArgs{
+		Stream: testStream, Group: testGroup, Start: id, End: id, Count: 1,
+	}).Result()
+	if err != nil {
+		t.Fatalf("XPendingExt() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("pending entries for %s = %+v, want one", id, entries)
+	}
+	return entries[0].RetryCount
+}
+
+func (h *redisHarness) deadLettered(t *testing.T) []redis.XMessage {
+	t.Helper()
+
+	messages, err := h.client.XRange(context.Background(), testDeadLetter, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange() error = %v", err)
+	}
+	return messages
+}
+
+func eventually(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+const testVotePayload = `{"id":"vote-1","participanteId":7,"sessionId":"session-1","timestamp":"2024-01-01T12:00:00Z"}`
+
+func TestRedisConsumerAckRemovesEntryFromPendingList(t *testing.T) {
+	h := startRedisConsumer(t, 3)
+	h.add(t, testVotePayload)
+
+	delivery := h.receive(t)
+	if delivery.Vote().ID != "vote-1" {
+		t.Fatalf("vote ID = %s, want vote-1", delivery.Vote().ID)
+	}
+	delivery.Ack()
+
+	eventually(t, func() bool { return h.pending(t) == 0 }, "acked entry still pending")
+
+	h.expire()
+	h.expectNoDelivery(t, testVisibility)
+}
+
+func TestRedisConsumerReclaimsNackedEntry(t *testing.T) {
+	h := startRedisConsumer(t, 3)
+	id := h.add(t, testVotePayload)
+
+	h.receive(t).Nack(errors.New("database unavailable"))
+	h.expire()
+
+	delivery := h.receive(t)
+	if delivery.Vote().ID != "vote-1" {
+		t.Fatalf("reclaimed vote ID = %s, want vote-1", delivery.Vote().ID)
+	}
+
+	if got := h.deliveryCount(t, id); got != 2 {
+		t.Fatalf("delivery count = %d, want 2", got)
+	}
+}
+
+func TestRedisConsumerDoesNotReclaimItsOwnInflightEntry(t *testing.T) {
+	h := startRedisConsumer(t, 3)
+	h.add(t, testVotePayload)
+
+	delivery := h.receive(t)
+	h.expire()
+	h.expectNoDelivery(t, 2*testVisibility)
+
+	delivery.Ack()
+	eventually(t, func() bool { return h.pending(t) == 0 }, "acked entry still pending")
+}
+
+func TestRedisConsumerDeadLettersEntryPastMaxDeliveries(t *testing.T) {
+	h := startRedisConsumer(t, 2)
+	id := h.add(t, testVotePayload)
+
+	h.receive(t).Nack(errors.New("first failure"))
+	h.expire()
+	h.receive(t).Nack(errors.New("second failure"))
+	h.expire()
+
+	eventually(t, func() bool { return len(h.deadLettered(t)) == 1 }, "entry was not dead lettered")
+
+	dead := h.deadLettered(t)[0]
+	if dead.Values[RedisFieldSource] != id {
+		t.Errorf("dead letter source = %v, want %s", dead.Values[RedisFieldSource], id)
+	}
+	if dead.Values[RedisFieldPayload] != testVotePayload {
+		t.Errorf("dead letter payload = %v", dead.Values[RedisFieldPayload])
+	}
+	if h.pending(t) != 0 {
+		t.Error("dead lettered entry still pending")
+	}
+	h.expectNoDelivery(t, testVisibility)
+}
+
+func TestRedisConsumerDeadLettersUndecodableEntry(t *testing.T) {
+	h := startRedisConsumer(t, 3)
+	h.add(t, "not json")
+
+	eventually(t, func() bool { return len(h.deadLettered(t)) == 1 }, "undecodable entry was not dead lettered")
+
+	if h.pending(t) != 0 {
+		t.Error("dead lettered entry still pending")
+	}
+}
+
+func TestRedisConsumerHoldsEntryWhileSchemaRegistryIsUnavailable(t *testing.T) {
+	registry := &stubSchemaRegistry{
+		err:      &schemaregistry.UnavailableError{Err: errors.New("connection refused")},
+		failures: 1,
+	}
+	decoders := NewDecoderRegistry()
+	decoders.RegisterContentType(ContentTypeAvro, NewAvroVoteDecoder(registry, time.Second))
+
+	h := startRedisConsumerWith(t, 1, decoders)
+	id := h.addWith(t, map[string]interface{}{
+		RedisFieldPayload: string(confluentPayload(t, 1, testAvroVote{ParticipanteID: 7, SessionID: "session-1", Timestamp: testVoteTime})),
+		HeaderContentType: ContentTypeAvro,
+	})
+
+	// past the visibility timeout the held entry would be reclaimed, and
+	// with one allowed delivery dead lettered, if the outage counted
+	h.expire()
+
+	delivery := h.receive(t)
+	if delivery.Vote().SessionID != "session-1" {
+		t.Fatalf("vote = %+v", delivery.Vote())
+	}
+	if got := h.deliveryCount(t, id); got != 1 {
+		t.Errorf("delivery count = %d, want 1", got)
+	}
+	if dead := h.deadLettered(t); len(dead) != 0 {
+		t.Errorf("dead lettered %d entries during the outage", len(dead))
+	}
+
+	delivery.Ack()
+	eventually(t, func() bool { return h.pending(t) == 0 }, "acked entry still pending")
+}
@@ -0,0 +1,149 @@
+package messaging
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderReplyTo       = "reply-to"
+	HeaderCorrelationID = "correlation-id"
+)
+
+// KafkaReplyPublisher answers messages that carry reply-to and
+// correlation-id headers with the final status of their vote, so the
+// producer that marked the vote SENT can confirm it. Replies are written
+// asynchronously; each one reports back through its done callback once the
+// broker acknowledged it, so the source offset is only acked after the
+// reply is durable. reply-to comes from the producer, so replies only go to
+// existing topics matching one of the allowed prefixes.
+type KafkaReplyPublisher struct {
+	writer   *kafka.Writer
+	prefixes []string
+
+	// callbacks still running after their reply settled
+	callbacks sync.WaitGroup
+}
+
+func NewKafkaReplyPublisher(brokers []string, prefixes []string) *KafkaReplyPublisher {
+	p := &KafkaReplyPublisher{
+		prefixes: prefixes,
+	}
+
+	p.writer = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+		Async:        true,
+		Completion:   p.complete,
+	}
+
+	return p
+}
+
+// complete runs the done callback of every reply in the written batch. The
+// callbacks may republish the source message, so they run off the writer's
+// goroutine.
+func (p *KafkaReplyPublisher) complete(messages []kafka.Message, err error) {
+	if err != nil {
+		log.Printf("Failed to publish %d vote replies: %v", len(messages), err)
+	}
+
+	for _, message := range messages {
+		done, ok := message.WriterData.(func(error))
+		if !ok {
+			continue
+		}
+
+		p.callbacks.Add(1)
+		go func() {
+			defer p.callbacks.Done()
+			done(err)
+		}()
+	}
+}
+
+func (p *KafkaReplyPublisher) allowed(topic string) bool {
+	for _, prefix := range p.prefixes {
+		if prefix != "" && strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reply reports vote as processed when reason is nil and as failed
+// otherwise; vote is nil when the message could not be decoded. done is
+// called exactly once: with nil when no reply is due or it was written,
+// with the write error otherwise.
+func (p *KafkaReplyPublisher) Reply(msg kafka.Message, vote *entity.Vote, reason error, done func(error)) {
+	replyTo := headerValue(msg, HeaderReplyTo)
+	correlationID := headerValue(msg, HeaderCorrelationID)
+	if replyTo == "" || correlationID == "" {
+		done(nil)
+		return
+	}
+
+	if !p.allowed(replyTo) {
+		log.Printf("Vote reply dropped, reply-to topic not allowed: topic=%s correlation=%s", replyTo, correlationID)
+		done(nil)
+		return
+	}
+
+	reply := &models.VoteReply{
+		CorrelationID: correlationID,
+		Status:        string(entity.VoteStatusFailed),
+		RepliedAt:     time.Now().UTC(),
+	}
+	if vote != nil {
+		reply.VoteID = vote.ID
+		reply.ProcessedAt = vote.ProcessedAt
+	}
+
+	switch {
+	case reason != nil:
+		message := reason.Error()
+		reply.Error = &message
+	case vote != nil:
+		reply.Status = string(vote.Status)
+	}
+
+	value, err := reply.ToJSON()
+	if err != nil {
+		log.Printf("Failed to encode vote reply: correlation=%s error=%v", correlationID, err)
+		done(nil)
+		return
+	}
+
+	record := kafka.Message{
+		Topic: replyTo,
+		Key:   []byte(correlationID),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderCorrelationID, Value: []byte(correlationID)},
+			{Key: HeaderContentType, Value: []byte(ContentTypeJSON)},
+		},
+		WriterData: done,
+	}
+
+	// the writer is async, so this only fails once it is closed
+	if err := p.writer.WriteMessages(context.Background(), record); err != nil {
+		log.Printf("Failed to queue vote reply: correlation=%s error=%v", correlationID, err)
+		done(err)
+	}
+}
+
+// Close flushes the replies still queued and waits for their callbacks.
+func (p *KafkaReplyPublisher) Close() error {
+	err := p.writer.Close()
+	p.callbacks.Wait()
+	return err
+}
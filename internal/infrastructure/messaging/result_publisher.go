@@ -0,0 +1,72 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"github.com/segmentio/kafka-go"
+)
+
+const HeaderEventType = "event-type"
+
+// KafkaVoteResultPublisher emits a VoteProcessed or VoteFailed event per
+// vote, keyed by vote ID so every event for a vote lands on one partition.
+type KafkaVoteResultPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaVoteResultPublisher(brokers []string, topic string) port.VoteResultPublisherPort {
+	return &KafkaVoteResultPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *KafkaVoteResultPublisher) PublishResults(ctx context.Context, votes []*entity.Vote) error {
+	records := make([]kafka.Message, 0, len(votes))
+
+	for _, vote := range votes {
+		event := models.NewVoteResultEvent(vote)
+		if event == nil {
+			continue
+		}
+
+		value, err := event.ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to encode result event for vote %s: %w", vote.ID, err)
+		}
+
+		records = append(records, kafka.Message{
+			Key:   []byte(vote.ID),
+			Value: value,
+			Headers: []kafka.Header{
+				{Key: HeaderEventType, Value: []byte(event.EventType)},
+				{Key: HeaderContentType, Value: []byte(ContentTypeJSON)},
+			},
+		})
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := p.writer.WriteMessages(ctx, records...); err != nil {
+		return fmt.Errorf("failed to publish vote results: %w", err)
+	}
+
+	return nil
+}
+
+func (p *KafkaVoteResultPublisher) Close() error {
+	return p.writer.Close()
+}
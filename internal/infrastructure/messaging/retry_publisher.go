@@ -0,0 +1,170 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderRetryAttempt         = "retry-attempt"
+	HeaderRetryError           = "retry-error"
+	HeaderRetrySource          = "retry-source-topic"
+	HeaderRetrySourcePartition = "retry-source-partition"
+	HeaderRetrySourceOffset    = "retry-source-offset"
+)
+
+type RetryTier struct {
+	Topic string
+	Delay time.Duration
+}
+
+type RetryPolicy struct {
+	Tiers       []RetryTier
+	MaxAttempts int
+}
+
+// NewRetryPolicy builds one tier per backoff step, each delay being the
+// previous one times multiplier, e.g. votos.retry.1m and votos.retry.10m.
+func NewRetryPolicy(topic string, tiers int, backoff time.Duration, multiplier int, maxAttempts int) RetryPolicy {
+	policy := RetryPolicy{MaxAttempts: maxAttempts}
+
+	delay := backoff
+	for i := 0; i < tiers; i++ {
+		policy.Tiers = append(policy.Tiers, RetryTier{
+			Topic: fmt.Sprintf("%s.retry.%s", topic, compactDuration(delay)),
+			Delay: delay,
+		})
+		delay *= time.Duration(multiplier)
+	}
+
+	return policy
+}
+
+func (p RetryPolicy) Enabled() bool {
+	return len(p.Tiers) > 0 && p.MaxAttempts > 0
+}
+
+func (p RetryPolicy) TierFor(attempt int) RetryTier {
+	index := attempt - 1
+	if index >= len(p.Tiers) {
+		index = len(p.Tiers) - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	return p.Tiers[index]
+}
+
+type KafkaRetryPublisher struct {
+	writer *kafka.Writer
+	policy RetryPolicy
+}
+
+func NewKafkaRetryPublisher(brokers []string, policy RetryPolicy) *KafkaRetryPublisher {
+	return &KafkaRetryPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+		policy: policy,
+	}
+}
+
+// Publish schedules msg for another attempt. It returns false when the
+// attempts are exhausted and the message belongs in the dead letter topic.
+func (p *KafkaRetryPublisher) Publish(ctx context.Context, msg kafka.Message, reason error) (bool, error) {
+	attempt := retryAttempt(msg) + 1
+	if !p.policy.Enabled() || attempt > p.policy.MaxAttempts {
+		return false, nil
+	}
+
+	tier := p.policy.TierFor(attempt)
+	record := retryRecord(msg, tier, attempt, reason)
+
+	if err := p.writer.WriteMessages(ctx, record); err != nil {
+		return false, fmt.Errorf("failed to publish to retry topic %s: %w", tier.Topic, err)
+	}
+
+	return true, nil
+}
+
+func (p *KafkaRetryPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// retryRecord copies msg onto the tier topic, replacing any retry headers
+// it already carries so the origin stays the first consumed message.
+func retryRecord(msg kafka.Message, tier RetryTier, attempt int, reason error) kafka.Message {
+	topic, partition, offset := messageOrigin(msg)
+
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	for _, header := range msg.Headers {
+		switch header.Key {
+		case HeaderRetryAttempt, HeaderRetryError, HeaderRetrySource, HeaderRetrySourcePartition, HeaderRetrySourceOffset:
+			continue
+		}
+		headers = append(headers, header)
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderRetryAttempt, Value: []byte(strconv.Itoa(attempt))},
+		kafka.Header{Key: HeaderRetryError, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderRetrySource, Value: []byte(topic)},
+		kafka.Header{Key: HeaderRetrySourcePartition, Value: []byte(strconv.Itoa(partition))},
+		kafka.Header{Key: HeaderRetrySourceOffset, Value: []byte(strconv.FormatInt(offset, 10))},
+	)
+
+	return kafka.Message{
+		Topic:   tier.Topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+func retryAttempt(msg kafka.Message) int {
+	attempt, err := strconv.Atoi(headerValue(msg, HeaderRetryAttempt))
+	if err != nil {
+		return 0
+	}
+	return attempt
+}
+
+// messageOrigin returns where msg was first consumed: its own coordinates,
+// or those carried over from the main topic when it is a retry.
+func messageOrigin(msg kafka.Message) (string, int, int64) {
+	topic := headerValue(msg, HeaderRetrySource)
+	if topic == "" {
+		return msg.Topic, msg.Partition, msg.Offset
+	}
+
+	partition, err := strconv.Atoi(headerValue(msg, HeaderRetrySourcePartition))
+	if err != nil {
+		return msg.Topic, msg.Partition, msg.Offset
+	}
+	offset, err := strconv.ParseInt(headerValue(msg, HeaderRetrySourceOffset), 10, 64)
+	if err != nil {
+		return msg.Topic, msg.Partition, msg.Offset
+	}
+
+	return topic, partition, offset
+}
+
+func compactDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d >= time.Minute && d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d >= time.Second && d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	return strings.ReplaceAll(d.String(), ".", "_")
+}
@@ -0,0 +1,142 @@
+package messaging
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func headerValues(msg kafka.Message, key string) []string {
+	var values []string
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			values = append(values, string(header.Value))
+		}
+	}
+	return values
+}
+
+func TestNewRetryPolicyNamesTiersByDelay(t *testing.T) {
+	policy := NewRetryPolicy("votos", 3, 30*time.Second, 10, 5)
+
+	want := []string{"votos.retry.30s", "votos.retry.5m", "votos.retry.50m"}
+	if len(policy.Tiers) != len(want) {
+		t.Fatalf("got %d tiers, want %d", len(policy.Tiers), len(want))
+	}
+	for i, topic := range want {
+		if policy.Tiers[i].Topic != topic {
+			t.Errorf("tier %d topic = %s, want %s", i, policy.Tiers[i].Topic, topic)
+		}
+	}
+
+	tests := []struct {
+		attempt int
+		want    string
+	}{
+		{attempt: 0, want: "votos.retry.30s"},
+		{attempt: 1, want: "votos.retry.30s"},
+		{attempt: 2, want: "votos.retry.5m"},
+		{attempt: 5, want: "votos.retry.50m"},
+	}
+	for _, tt := range tests {
+		if got := policy.TierFor(tt.attempt).Topic; got != tt.want {
+			t.Errorf("TierFor(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryRecordHeaders(t *testing.T) {
+	tier := RetryTier{Topic: "votos.retry.5m", Delay: 5 * time.Minute}
+
+	tests := []struct {
+		name          string
+		msg           kafka.Message
+		attempt       int
+		wantSource    string
+		wantPartition string
+		wantOffset    string
+	}{
+		{
+			name: "first retry records the consumed coordinates",
+			msg: kafka.Message{
+				Topic: "votos", Partition: 2, Offset: 41,
+				Headers: []kafka.Header{{Key: "content-type", Value: []byte("application/json")}},
+			},
+			attempt:       1,
+			wantSource:    "votos",
+			wantPartition: "2",
+			wantOffset:    "41",
+		},
+		{
+			name: "later retry keeps the original coordinates",
+			msg: kafka.Message{
+				Topic: "votos.retry.30s", Partition: 0, Offset: 7,
+				Headers: []kafka.Header{
+					{Key: "content-type", Value: []byte("application/json")},
+					{Key: HeaderRetryAttempt, Value: []byte("1")},
+					{Key: HeaderRetryError, Value: []byte("earlier failure")},
+					{Key: HeaderRetrySource, Value: []byte("votos")},
+					{Key: HeaderRetrySourcePartition, Value: []byte("2")},
+					{Key: HeaderRetrySourceOffset, Value: []byte("41")},
+				},
+			},
+			attempt:       2,
+			wantSource:    "votos",
+			wantPartition: "2",
+			wantOffset:    "41",
+		},
+		{
+			name: "malformed origin falls back to the consumed coordinates",
+			msg: kafka.Message{
+				Topic: "votos.retry.30s", Partition: 1, Offset: 9,
+				Headers: []kafka.Header{
+					{Key: HeaderRetrySource, Value: []byte("votos")},
+					{Key: HeaderRetrySourcePartition, Value: []byte("not a number")},
+				},
+			},
+			attempt:       2,
+			wantSource:    "votos.retry.30s",
+			wantPartition: "1",
+			wantOffset:    "9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.msg.Key = []byte("session-1")
+			tt.msg.Value = []byte("payload")
+
+			record := retryRecord(tt.msg, tier, tt.attempt, errors.New("database unavailable"))
+
+			if record.Topic != tier.Topic {
+				t.Errorf("topic = %s, want %s", record.Topic, tier.Topic)
+			}
+			if string(record.Key) != "session-1" || string(record.Value) != "payload" {
+				t.Errorf("key, value = %q, %q", record.Key, record.Value)
+			}
+
+			want := map[string]string{
+				HeaderRetryAttempt:         strconv.Itoa(tt.attempt),
+				HeaderRetryError:           "database unavailable",
+				HeaderRetrySource:          tt.wantSource,
+				HeaderRetrySourcePartition: tt.wantPartition,
+				HeaderRetrySourceOffset:    tt.wantOffset,
+			}
+			for key, value := range want {
+				values := headerValues(record, key)
+				if len(values) != 1 || values[0] != value {
+					t.Errorf("header %s = %v, want [%s]", key, values, value)
+				}
+			}
+			if got := headerValue(record, "content-type"); got != headerValue(tt.msg, "content-type") {
+				t.Errorf("content-type = %q, want it carried over", got)
+			}
+			if got := retryAttempt(record); got != tt.attempt {
+				t.Errorf("retryAttempt() = %d, want %d", got, tt.attempt)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// FileSchemaRegistry serves schemas from a directory holding one <id>.avsc
+// file per schema ID, standing in for a schema registry in local runs.
+type FileSchemaRegistry struct {
+	dir string
+}
+
+func NewFileSchemaRegistry(dir string) *FileSchemaRegistry {
+	return &FileSchemaRegistry{
+		dir: dir,
+	}
+}
+
+func (r *FileSchemaRegistry) GetSchema(ctx context.Context, id int) (string, error) {
+	path := filepath.Join(r.dir, strconv.Itoa(id)+".avsc")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("schema %d: %w", id, ErrSchemaNotFound)
+		}
+		return "", fmt.Errorf("failed to read schema file %s: %w", path, err)
+	}
+
+	return string(content), nil
+}
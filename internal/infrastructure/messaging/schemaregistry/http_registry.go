@@ -0,0 +1,74 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type HTTPSchemaRegistry struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+type schemaResponse struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+func NewHTTPSchemaRegistry(baseURL, username, password string, timeout time.Duration) *HTTPSchemaRegistry {
+	return &HTTPSchemaRegistry{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (r *HTTPSchemaRegistry) GetSchema(ctx context.Context, id int) (string, error) {
+	url := fmt.Sprintf("%s/schemas/ids/%d", r.baseURL, id)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build schema request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", &UnavailableError{Err: fmt.Errorf("failed to fetch schema %d: %w", id, err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("schema %d: %w", id, ErrSchemaNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("schema registry returned %d for schema %d: %s", resp.StatusCode, id, body)
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return "", &UnavailableError{Err: err}
+		}
+		return "", err
+	}
+
+	var schema schemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return "", fmt.Errorf("failed to decode schema %d: %w", id, err)
+	}
+
+	if schema.SchemaType != "" && schema.SchemaType != "AVRO" {
+		return "", fmt.Errorf("schema %d has unsupported type %s", id, schema.SchemaType)
+	}
+
+	return schema.Schema, nil
+}
@@ -0,0 +1,145 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPSchemaRegistryGetSchema(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		want            string
+		wantErr         string
+		wantNotFound    bool
+		wantUnavailable bool
+	}{
+		{
+			name:   "avro schema",
+			status: http.StatusOK,
+			body:   `{"schema":"\"string\""}`,
+			want:   `"string"`,
+		},
+		{
+			name:   "explicit avro type",
+			status: http.StatusOK,
+			body:   `{"schema":"\"long\"","schemaType":"AVRO"}`,
+			want:   `"long"`,
+		},
+		{
+			name:    "other schema type",
+			status:  http.StatusOK,
+			body:    `{"schema":"{}","schemaType":"JSON"}`,
+			wantErr: "unsupported type JSON",
+		},
+		{
+			name:    "malformed response",
+			status:  http.StatusOK,
+			body:    `{"schema":`,
+			wantErr: "failed to decode schema 1",
+		},
+		{
+			name:         "unknown id",
+			status:       http.StatusNotFound,
+			body:         `{"error_code":40403}`,
+			wantErr:      "schema not found",
+			wantNotFound: true,
+		},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			wantErr: "returned 401",
+		},
+		{
+			name:            "server error",
+			status:          http.StatusServiceUnavailable,
+			body:            "maintenance",
+			wantErr:         "returned 503 for schema 1: maintenance",
+			wantUnavailable: true,
+		},
+		{
+			name:            "rate limited",
+			status:          http.StatusTooManyRequests,
+			wantErr:         "returned 429",
+			wantUnavailable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/schemas/ids/1" {
+					t.Errorf("path = %s, want /schemas/ids/1", r.URL.Path)
+				}
+				if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+					t.Errorf("basic auth = %s, %s, %v", user, password, ok)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			registry := NewHTTPSchemaRegistry(server.URL+"/", "user", "secret", time.Second)
+			got, err := registry.GetSchema(context.Background(), 1)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GetSchema() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("GetSchema() = %s, want %s", got, tt.want)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("GetSchema() = %s, want error", got)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetSchema() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrSchemaNotFound) != tt.wantNotFound {
+				t.Errorf("errors.Is(%v, ErrSchemaNotFound) = %v, want %v", err, !tt.wantNotFound, tt.wantNotFound)
+			}
+			if IsUnavailable(err) != tt.wantUnavailable {
+				t.Errorf("IsUnavailable(%v) = %v, want %v", err, !tt.wantUnavailable, tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestHTTPSchemaRegistryUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewHTTPSchemaRegistry(server.URL, "", "", time.Second).GetSchema(context.Background(), 1)
+	if !IsUnavailable(err) {
+		t.Fatalf("GetSchema() error = %v, want registry unavailable", err)
+	}
+}
+
+func TestFileSchemaRegistryGetSchema(t *testing.T) {
+	registry := NewFileSchemaRegistry("../../../../api/vote/v1/schemas")
+
+	schema, err := registry.GetSchema(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetSchema(1) error = %v", err)
+	}
+	if !strings.Contains(schema, `"name": "Vote"`) {
+		t.Errorf("GetSchema(1) = %s", schema)
+	}
+
+	_, err = registry.GetSchema(context.Background(), 2)
+	if !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("GetSchema(2) error = %v, want ErrSchemaNotFound", err)
+	}
+	if IsUnavailable(err) {
+		t.Errorf("GetSchema(2) error = %v reported as registry unavailable", err)
+	}
+}
@@ -0,0 +1,34 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrSchemaNotFound = errors.New("schema not found")
+
+type SchemaRegistry interface {
+	GetSchema(ctx context.Context, id int) (string, error)
+}
+
+// UnavailableError reports a registry that could not be reached or failed
+// on its side, e.g. a timeout or a 5xx. Unlike a missing schema it says
+// nothing about the message, so the fetch is worth retrying.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnavailable reports whether err, or any error it wraps, is an
+// UnavailableError.
+func IsUnavailable(err error) bool {
+	var unavailable *UnavailableError
+	return errors.As(err, &unavailable)
+}
@@ -0,0 +1,407 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/schemaregistry"
+	"github.com/segmentio/kafka-go"
+)
+
+// fetch errors back off exponentially between these bounds, so a broker
+// outage stalls the reader instead of stopping it
+const (
+	fetchBackoffMin = 500 * time.Millisecond
+	fetchBackoffMax = 30 * time.Second
+)
+
+// publishAttempts bounds how often a retry publish is tried before the
+// message falls through to the dead letter topic
+const publishAttempts = 5
+
+type KafkaVoteConsumer struct {
+	topics         []*topicReader
+	buffer         int
+	commitInterval time.Duration
+
+	decoders    *DecoderRegistry
+	deadLetters *KafkaDeadLetterPublisher
+	retries     *KafkaRetryPublisher
+	replies     *KafkaReplyPublisher
+
+	wg sync.WaitGroup
+}
+
+// topicReader consumes one topic of the pipeline: the main vote topic has
+// no delay, retry tiers hold each message until its backoff has elapsed.
+type topicReader struct {
+	reader  *kafka.Reader
+	delay   time.Duration
+	offsets *offsetTracker
+}
+
+type kafkaVoteDelivery struct {
+	ctx      context.Context
+	consumer *KafkaVoteConsumer
+	topic    *topicReader
+	vote     *entity.Vote
+	tracked  *trackedOffset
+}
+
+func (d *kafkaVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+// the offset is acked once the reply, if any, was written; a lost reply
+// sends the message through the retry topics so the reply is sent again
+func (d *kafkaVoteDelivery) Ack() {
+	d.consumer.replies.Reply(d.tracked.msg, d.vote, nil, func(err error) {
+		if err != nil {
+			d.Nack(fmt.Errorf("failed to publish vote reply: %w", err))
+			return
+		}
+		d.topic.offsets.ack(d.tracked)
+	})
+}
+
+func (d *kafkaVoteDelivery) Nack(err error) {
+	d.consumer.retry(d.ctx, d.topic, d.tracked, d.vote, err)
+}
+
+func (d *kafkaVoteDelivery) Reject(err error) {
+	d.consumer.deadLetter(d.ctx, d.topic, d.tracked, d.vote, err)
+}
+
+func NewKafkaVoteConsumer(cfg *config.KafkaConfig, decoders *DecoderRegistry) port.VoteConsumerPort {
+	policy := NewRetryPolicy(cfg.Topic, cfg.RetryTiers, cfg.RetryBackoff, cfg.RetryMultiplier, cfg.RetryMaxAttempts)
+
+	topics := []*topicReader{newTopicReader(cfg, cfg.Topic, 0)}
+	if policy.Enabled() {
+		for _, tier := range policy.Tiers {
+			topics = append(topics, newTopicReader(cfg, tier.Topic, tier.Delay))
+		}
+	}
+
+	return &KafkaVoteConsumer{
+		topics:         topics,
+		buffer:         cfg.BatchSize,
+		commitInterval: cfg.CommitInterval,
+		decoders:       decoders,
+		deadLetters:    NewKafkaDeadLetterPublisher(cfg.Brokers, cfg.DeadLetterTopic),
+		retries:        NewKafkaRetryPublisher(cfg.Brokers, policy),
+		replies:        NewKafkaReplyPublisher(cfg.Brokers, cfg.ReplyTopicPrefixes),
+	}
+}
+
+func newTopicReader(cfg *config.KafkaConfig, topic string, delay time.Duration) *topicReader {
+	return &topicReader{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Brokers,
+			Topic:   topic,
+			GroupID: cfg.ConsumerGroup,
+		}),
+		delay:   delay,
+		offsets: newOffsetTracker(),
+	}
+}
+
+func (c *KafkaVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	deliveries := make(chan port.VoteDelivery, c.buffer)
+
+	var readers sync.WaitGroup
+	for _, topic := range c.topics {
+		readers.Add(1)
+		go func(topic *topicReader) {
+			defer readers.Done()
+			c.readLoop(ctx, topic, deliveries)
+		}(topic)
+	}
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		readers.Wait()
+		close(deliveries)
+	}()
+	go func() {
+		defer c.wg.Done()
+		c.commitLoop(ctx)
+	}()
+
+	return deliveries, nil
+}
+
+func (c *KafkaVoteConsumer) readLoop(ctx context.Context, topic *topicReader, deliveries chan<- port.VoteDelivery) {
+	backoff := fetchBackoffMin
+
+	for {
+		msg, err := topic.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error reading message from Kafka, retrying in %s: topic=%s error=%v",
+				backoff, topic.reader.Config().Topic, err)
+			if !waitUntil(ctx, time.Now().Add(backoff)) {
+				return
+			}
+			backoff = min(backoff*2, fetchBackoffMax)
+			continue
+		}
+		backoff = fetchBackoffMin
+
+		tracked := topic.offsets.track(msg)
+
+		if !waitUntil(ctx, msg.Time.Add(topic.delay)) {
+			return
+		}
+
+		vote, err := c.decodeHolding(ctx, msg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.deadLetter(ctx, topic, tracked, nil, err)
+			continue
+		}
+
+		delivery := &kafkaVoteDelivery{
+			ctx:      ctx,
+			consumer: c,
+			topic:    topic,
+			vote:     vote,
+			tracked:  tracked,
+		}
+
+		select {
+		case deliveries <- delivery:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func waitUntil(ctx context.Context, due time.Time) bool {
+	wait := time.Until(due)
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retry moves a failed message to its next retry tier, or to the dead letter
+// topic once attempts run out. A retry topic that stays unreachable also
+// sends the message to the dead letter topic, so its offset can move on.
+func (c *KafkaVoteConsumer) retry(ctx context.Context, topic *topicReader, tracked *trackedOffset, vote *entity.Vote, reason error) {
+	msg := tracked.msg
+
+	var scheduled bool
+	err := publishWithBackoff(ctx, func() error {
+		var err error
+		scheduled, err = c.retries.Publish(ctx, msg, reason)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to schedule retry: topic=%s partition=%d offset=%d error=%v",
+			msg.Topic, msg.Partition, msg.Offset, err)
+		c.deadLetter(ctx, topic, tracked, vote, fmt.Errorf("retry could not be scheduled: %w", reason))
+		return
+	}
+
+	if !scheduled {
+		c.deadLetter(ctx, topic, tracked, vote, fmt.Errorf("retry attempts exhausted: %w", reason))
+		return
+	}
+
+	log.Printf("Vote scheduled for retry: topic=%s partition=%d offset=%d attempt=%d error=%v",
+		msg.Topic, msg.Partition, msg.Offset, retryAttempt(msg)+1, reason)
+
+	topic.offsets.ack(tracked)
+}
+
+// deadLetter acks the message only once it is safely on the dead letter
+// topic. It is the last fallback, so a failing publish is retried with
+// backoff until it succeeds, holding the partition like decodeHolding; only
+// a cancelled ctx gives up, leaving the offset for redelivery. vote is nil
+// when the message could not be decoded.
+func (c *KafkaVoteConsumer) deadLetter(ctx context.Context, topic *topicReader, tracked *trackedOffset, vote *entity.Vote, reason error) {
+	msg := tracked.msg
+	log.Printf("Sending message to dead letter topic: topic=%s partition=%d offset=%d reason=%v",
+		msg.Topic, msg.Partition, msg.Offset, reason)
+
+	backoff := fetchBackoffMin
+	for {
+		err := c.deadLetters.Publish(ctx, msg, reason)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			log.Printf("Dead letter publish interrupted, offset left for redelivery: topic=%s partition=%d offset=%d error=%v",
+				msg.Topic, msg.Partition, msg.Offset, err)
+			return
+		}
+
+		log.Printf("Failed to dead letter message, holding partition: topic=%s partition=%d offset=%d retry_in=%s error=%v",
+			msg.Topic, msg.Partition, msg.Offset, backoff, err)
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return
+		}
+		backoff = min(backoff*2, fetchBackoffMax)
+	}
+
+	// the failure is already recorded on the dead letter topic, so a reply
+	// that cannot be written does not hold the offset back
+	c.replies.Reply(msg, vote, reason, func(err error) {
+		if err != nil {
+			log.Printf("Dead lettered message left without reply: topic=%s partition=%d offset=%d error=%v",
+				msg.Topic, msg.Partition, msg.Offset, err)
+		}
+		topic.offsets.ack(tracked)
+	})
+}
+
+// publishWithBackoff tries publish up to publishAttempts times, backing off
+// between tries, and returns the last error if none succeeded.
+func publishWithBackoff(ctx context.Context, publish func() error) error {
+	backoff := fetchBackoffMin
+
+	for attempt := 1; ; attempt++ {
+		err := publish()
+		if err == nil || attempt == publishAttempts {
+			return err
+		}
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return err
+		}
+		backoff = min(backoff*2, fetchBackoffMax)
+	}
+}
+
+func (c *KafkaVoteConsumer) commitLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.commitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.commit(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *KafkaVoteConsumer) commit(ctx context.Context) {
+	for _, topic := range c.topics {
+		msgs := topic.offsets.committable()
+		if len(msgs) == 0 {
+			continue
+		}
+
+		if err := topic.reader.CommitMessages(ctx, msgs...); err != nil {
+			log.Printf("Failed to commit offsets: %v", err)
+			topic.offsets.restore(msgs)
+		}
+	}
+}
+
+// decodeHolding decodes msg, holding the partition while the schema registry
+// is unavailable: every Avro vote would fail the same way, so the decode is
+// retried with backoff instead of dead lettering the message.
+func (c *KafkaVoteConsumer) decodeHolding(ctx context.Context, msg kafka.Message) (*entity.Vote, error) {
+	backoff := fetchBackoffMin
+
+	for {
+		vote, err := c.decode(msg)
+		if err == nil || !schemaregistry.IsUnavailable(err) {
+			return vote, err
+		}
+
+		log.Printf("Schema registry unavailable, holding partition: topic=%s partition=%d offset=%d retry_in=%s error=%v",
+			msg.Topic, msg.Partition, msg.Offset, backoff, err)
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return nil, err
+		}
+		backoff = min(backoff*2, fetchBackoffMax)
+	}
+}
+
+func (c *KafkaVoteConsumer) decode(msg kafka.Message) (*entity.Vote, error) {
+	message, err := c.decoders.Decode(msg.Value, headerMap(msg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vote message: %w", err)
+	}
+
+	if err := message.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid vote message: %w", err)
+	}
+
+	topic, partition, offset := messageOrigin(msg)
+	message.Origin = fmt.Sprintf("kafka:%s/%d/%d", topic, partition, offset)
+
+	vote, err := message.ToEntity()
+	if err != nil {
+		return nil, err
+	}
+
+	// the producer awaits a reply, so the vote arrives as SENT rather than RECEIVED
+	if headerValue(msg, HeaderReplyTo) != "" && headerValue(msg, HeaderCorrelationID) != "" {
+		vote.Status = entity.VoteStatusSent
+	}
+
+	// a retried vote resumes from the FAILED state of its previous attempt
+	if retryAttempt(msg) > 0 {
+		lastError := headerValue(msg, HeaderRetryError)
+		vote.Status = entity.VoteStatusFailed
+		vote.ProcessingError = &lastError
+	}
+
+	return vote, nil
+}
+
+func (c *KafkaVoteConsumer) Close() error {
+	c.wg.Wait()
+
+	// queued replies ack their offsets, or republish their message, once
+	// written, so they are flushed before committing and before the retry
+	// and dead letter publishers go away
+	if err := c.replies.Close(); err != nil {
+		log.Printf("Error closing reply publisher: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.commit(ctx)
+
+	if err := c.retries.Close(); err != nil {
+		log.Printf("Error closing retry publisher: %v", err)
+	}
+
+	if err := c.deadLetters.Close(); err != nil {
+		log.Printf("Error closing dead letter publisher: %v", err)
+	}
+
+	var closeErr error
+	for _, topic := range c.topics {
+		if err := topic.reader.Close(); err != nil {
+			closeErr = fmt.Errorf("failed to close kafka reader: %w", err)
+		}
+	}
+
+	return closeErr
+}
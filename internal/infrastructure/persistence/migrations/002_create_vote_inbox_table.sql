@@ -0,0 +1,20 @@
+-- gen_random_uuid() is built in from PostgreSQL 13; older servers get it from pgcrypto
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS vote_inbox (
+    id BIGSERIAL PRIMARY KEY,
+    vote_id VARCHAR(255) NOT NULL DEFAULT gen_random_uuid()::text,
+    participant_id INTEGER NOT NULL,
+    session_id VARCHAR(255) NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    leased_by VARCHAR(255) NULL,
+    leased_until TIMESTAMP NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_inbox_status_id ON vote_inbox(status, id);
+CREATE INDEX IF NOT EXISTS idx_vote_inbox_leased_until ON vote_inbox(leased_until) WHERE status = 'leased';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS vote_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(255) NOT NULL,
+    session_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL,
+    transaction_id BIGINT NOT NULL DEFAULT txid_current()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_outbox_undelivered ON vote_outbox(transaction_id, id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vote_outbox_delivered_at ON vote_outbox(delivered_at) WHERE delivered_at IS NOT NULL;
\ No newline at end of file
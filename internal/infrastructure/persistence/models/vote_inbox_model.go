@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+)
+
+const (
+	InboxStatusPending = "pending"
+	InboxStatusLeased  = "leased"
+	InboxStatusDead    = "dead"
+)
+
+type VoteInboxModel struct {
+	ID            int64     `db:"id"`
+	VoteID        string    `db:"vote_id"`
+	ParticipantID int       `db:"participant_id"`
+	SessionID     string    `db:"session_id"`
+	Timestamp     time.Time `db:"timestamp"`
+	Attempts      int       `db:"attempts"`
+}
+
+func (m *VoteInboxModel) ToEntity() *entity.Vote {
+	return entity.NewVoteFromData(
+		m.VoteID,
+		m.ParticipantID,
+		m.SessionID,
+		m.Timestamp,
+		entity.VoteStatusReceived,
+	)
+}
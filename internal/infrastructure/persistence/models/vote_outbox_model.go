@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	messagingmodels "github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+type VoteOutboxModel struct {
+	ID          int64      `db:"id"`
+	AggregateID string     `db:"aggregate_id"`
+	SessionID   string     `db:"session_id"`
+	EventType   string     `db:"event_type"`
+	Payload     []byte     `db:"payload"`
+	CreatedAt   time.Time  `db:"created_at"`
+	DeliveredAt *time.Time `db:"delivered_at"`
+}
+
+// NewProcessedVoteOutboxModel builds the VoteProcessed event for a vote
+// being saved. The row commits with the vote itself, so the event already
+// describes the vote as processed, at processedAt, when its row was written.
+func NewProcessedVoteOutboxModel(vote *entity.Vote, processedAt time.Time) (*VoteOutboxModel, error) {
+	processed := *vote
+	processed.Status = entity.VoteStatusProcessed
+	processed.ProcessedAt = &processedAt
+	processed.ProcessingError = nil
+
+	return newVoteOutboxModel(&processed, processedAt)
+}
+
+// NewFailedVoteOutboxModel builds the VoteFailed event for a vote that could
+// not be saved; it returns nil for a vote that has not failed.
+func NewFailedVoteOutboxModel(vote *entity.Vote) (*VoteOutboxModel, error) {
+	if vote.Status != entity.VoteStatusFailed {
+		return nil, nil
+	}
+
+	return newVoteOutboxModel(vote, time.Now().UTC())
+}
+
+func newVoteOutboxModel(vote *entity.Vote, createdAt time.Time) (*VoteOutboxModel, error) {
+	event := messagingmodels.NewVoteResultEvent(vote)
+
+	payload, err := event.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox event for vote %s: %w", vote.ID, err)
+	}
+
+	return &VoteOutboxModel{
+		AggregateID: vote.ID,
+		SessionID:   vote.SessionID,
+		EventType:   event.EventType,
+		Payload:     payload,
+		CreatedAt:   createdAt,
+	}, nil
+}
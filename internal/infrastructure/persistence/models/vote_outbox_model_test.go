@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	messagingmodels "github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+func TestNewProcessedVoteOutboxModelUsesWriteTime(t *testing.T) {
+	vote := entity.NewVoteFromData("vote-1", 7, "session-1", time.Now().UTC(), entity.VoteStatusProcessing)
+	writtenAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	model, err := NewProcessedVoteOutboxModel(vote, writtenAt)
+	if err != nil {
+		t.Fatalf("NewProcessedVoteOutboxModel() error = %v", err)
+	}
+
+	var event messagingmodels.VoteResultEvent
+	if err := json.Unmarshal(model.Payload, &event); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if event.EventType != messagingmodels.EventTypeVoteProcessed || event.Status != string(entity.VoteStatusProcessed) {
+		t.Errorf("event = %s with status %s", event.EventType, event.Status)
+	}
+	if event.ProcessedAt == nil || !event.ProcessedAt.Equal(writtenAt) {
+		t.Errorf("ProcessedAt = %v, want %v", event.ProcessedAt, writtenAt)
+	}
+	if !model.CreatedAt.Equal(writtenAt) {
+		t.Errorf("CreatedAt = %v, want %v", model.CreatedAt, writtenAt)
+	}
+	if vote.Status != entity.VoteStatusProcessing || vote.ProcessedAt != nil {
+		t.Errorf("vote was modified: %+v", vote)
+	}
+}
+
+func TestNewFailedVoteOutboxModel(t *testing.T) {
+	failed := entity.NewVoteFromData("vote-1", 7, "session-1", time.Now().UTC(), entity.VoteStatusProcessing)
+	failed.MarkAsFailedWithError(errors.New("rejected"))
+
+	tests := []struct {
+		name     string
+		vote     *entity.Vote
+		wantType string
+	}{
+		{"failed", failed, messagingmodels.EventTypeVoteFailed},
+		{"processing", entity.NewVoteFromData("vote-2", 7, "session-1", time.Now().UTC(), entity.VoteStatusProcessing), ""},
+		{"processed", entity.NewVoteFromData("vote-3", 7, "session-1", time.Now().UTC(), entity.VoteStatusProcessed), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := NewFailedVoteOutboxModel(tt.vote)
+			if err != nil {
+				t.Fatalf("NewFailedVoteOutboxModel() error = %v", err)
+			}
+			if tt.wantType == "" {
+				if model != nil {
+					t.Errorf("NewFailedVoteOutboxModel() = %s, want nil", model.EventType)
+				}
+				return
+			}
+			if model == nil || model.EventType != tt.wantType || model.AggregateID != tt.vote.ID || model.SessionID != "session-1" {
+				t.Errorf("NewFailedVoteOutboxModel() = %+v", model)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
+)
+
+// arbitrary key for pg_try_advisory_xact_lock, shared by every relay
+const outboxRelayLockKey = 7_318_204_551
+
+type OutboxPublisher interface {
+	PublishOutbox(ctx context.Context, events []*models.VoteOutboxModel) error
+	Close() error
+}
+
+// OutboxRelay drains vote_outbox in the order the writing transactions
+// began. ids are taken before commit, so a row with a lower id can become
+// visible after a higher one has been published; rows are therefore held
+// back until every transaction older than theirs has finished, and a row
+// can never turn up ahead of one already published. A session left idle in
+// a transaction stalls the relay until it ends.
+//
+// Only the relay holding the advisory lock drains at a time, and publishing
+// keyed by session_id keeps the order on the topic. Rows are marked delivered
+// in the transaction that read them, so a failed publish leaves them for the
+// next pass.
+type OutboxRelay struct {
+	db        *sql.DB
+	publisher OutboxPublisher
+
+	batch           int
+	poll            time.Duration
+	retention       time.Duration
+	cleanupInterval time.Duration
+
+	wg sync.WaitGroup
+}
+
+func NewOutboxRelay(database *Database, publisher OutboxPublisher, cfg *config.OutboxConfig) *OutboxRelay {
+	return &OutboxRelay{
+		db:              database.DB,
+		publisher:       publisher,
+		batch:           cfg.BatchSize,
+		poll:            cfg.PollInterval,
+		retention:       cfg.Retention,
+		cleanupInterval: cfg.CleanupInterval,
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		r.relayLoop(ctx)
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.cleanupLoop(ctx)
+	}()
+
+	log.Printf("Outbox relay started: polling every %s, batch %d", r.poll, r.batch)
+}
+
+func (r *OutboxRelay) Stop() {
+	r.wg.Wait()
+	log.Println("Outbox relay stopped")
+}
+
+func (r *OutboxRelay) Close() error {
+	return r.publisher.Close()
+}
+
+func (r *OutboxRelay) relayLoop(ctx context.Context) {
+	for {
+		delivered, err := r.drain(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to relay outbox events: %v", err)
+		}
+
+		if delivered == r.batch {
+			continue
+		}
+
+		select {
+		case <-time.After(r.poll):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) drain(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockKey).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire outbox relay lock: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	events, err := r.pending(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := r.publisher.PublishOutbox(ctx, events); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE vote_outbox SET delivered_at = NOW() WHERE id = ANY($1)",
+		pq.Array(ids),
+	); err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events delivered: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(events), nil
+}
+
+func (r *OutboxRelay) pending(ctx context.Context, tx *sql.Tx) ([]*models.VoteOutboxModel, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, aggregate_id, session_id, event_type, payload, created_at
+		FROM vote_outbox
+		WHERE delivered_at IS NULL
+		  AND transaction_id < txid_snapshot_xmin(txid_current_snapshot())
+		ORDER BY transaction_id, id
+		LIMIT $1
+	`, r.batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.VoteOutboxModel
+	for rows.Next() {
+		event := &models.VoteOutboxModel{}
+		if err := rows.Scan(
+			&event.ID,
+			&event.AggregateID,
+			&event.SessionID,
+			&event.EventType,
+			&event.Payload,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRelay) cleanupLoop(ctx context.Context) {
+	if r.cleanupInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := r.cleanup(ctx)
+			if err != nil {
+				log.Printf("Failed to clean up delivered outbox events: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("Removed %d delivered outbox events", removed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) cleanup(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM vote_outbox WHERE delivered_at < NOW() - ($1 * INTERVAL '1 millisecond')",
+		r.retention.Milliseconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
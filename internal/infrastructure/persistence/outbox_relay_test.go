@@ -0,0 +1,199 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	messagingmodels "github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
+)
+
+type recordingOutboxPublisher struct {
+	err    error
+	events []*models.VoteOutboxModel
+}
+
+func (p *recordingOutboxPublisher) PublishOutbox(ctx context.Context, events []*models.VoteOutboxModel) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *recordingOutboxPublisher) Close() error {
+	return nil
+}
+
+// published returns the aggregate IDs published for sessionID, in order;
+// the table may hold rows of other sessions left by earlier runs.
+func (p *recordingOutboxPublisher) published(sessionID string) []string {
+	var ids []string
+	for _, event := range p.events {
+		if event.SessionID == sessionID {
+			ids = append(ids, event.AggregateID)
+		}
+	}
+	return ids
+}
+
+func newTestSessionID(t *testing.T) string {
+	return fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+}
+
+func newFailedTestVote(id, sessionID string) *entity.Vote {
+	vote := entity.NewVoteFromData(id, 1, sessionID, time.Now().UTC(), entity.VoteStatusProcessing)
+	vote.MarkAsFailedWithError(errors.New("rejected"))
+	return vote
+}
+
+func newTestOutboxRelay(database *Database, publisher OutboxPublisher) *OutboxRelay {
+	return NewOutboxRelay(database, publisher, &config.OutboxConfig{BatchSize: 1000})
+}
+
+func TestOutboxRelayHoldsBackRowsBehindOpenTransaction(t *testing.T) {
+	sessionID := newTestSessionID(t)
+	database := openTestDatabase(t, sessionID)
+	ctx := context.Background()
+
+	publisher := &recordingOutboxPublisher{}
+	relay := newTestOutboxRelay(database, publisher)
+	results := NewPostgresOutboxResultPublisher(database)
+
+	// the first transaction writes before the second but commits after it
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+
+	first, err := models.NewFailedVoteOutboxModel(newFailedTestVote("vote-1", sessionID))
+	if err != nil {
+		t.Fatalf("NewFailedVoteOutboxModel() error = %v", err)
+	}
+	if err := insertOutboxEvents(ctx, tx, []*models.VoteOutboxModel{first}); err != nil {
+		t.Fatalf("insertOutboxEvents() error = %v", err)
+	}
+
+	if err := results.PublishResults(ctx, []*entity.Vote{newFailedTestVote("vote-2", sessionID)}); err != nil {
+		t.Fatalf("PublishResults() error = %v", err)
+	}
+
+	if _, err := relay.drain(ctx); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+	if got := publisher.published(sessionID); len(got) != 0 {
+		t.Fatalf("published %v while an older transaction was open", got)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if _, err := relay.drain(ctx); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+	got := publisher.published(sessionID)
+	if len(got) != 2 || got[0] != "vote-1" || got[1] != "vote-2" {
+		t.Errorf("published = %v, want [vote-1 vote-2]", got)
+	}
+}
+
+func TestOutboxRelayLeavesEventsUndeliveredWhenPublishFails(t *testing.T) {
+	sessionID := newTestSessionID(t)
+	database := openTestDatabase(t, sessionID)
+	ctx := context.Background()
+
+	results := NewPostgresOutboxResultPublisher(database)
+	if err := results.PublishResults(ctx, []*entity.Vote{newFailedTestVote("vote-1", sessionID)}); err != nil {
+		t.Fatalf("PublishResults() error = %v", err)
+	}
+
+	publisher := &recordingOutboxPublisher{err: errors.New("broker down")}
+	relay := newTestOutboxRelay(database, publisher)
+
+	if _, err := relay.drain(ctx); err == nil {
+		t.Fatal("drain() with failing publisher succeeded")
+	}
+
+	var undelivered int
+	if err := database.DB.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM vote_outbox WHERE session_id = $1 AND delivered_at IS NULL", sessionID,
+	).Scan(&undelivered); err != nil {
+		t.Fatalf("count undelivered error = %v", err)
+	}
+	if undelivered != 1 {
+		t.Fatalf("undelivered = %d, want 1", undelivered)
+	}
+
+	publisher.err = nil
+	if _, err := relay.drain(ctx); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+	if got := publisher.published(sessionID); len(got) != 1 || got[0] != "vote-1" {
+		t.Errorf("published = %v, want [vote-1]", got)
+	}
+}
+
+func TestOutboxRelayPublishesFailedVotesInOrderWithProcessed(t *testing.T) {
+	sessionID := newTestSessionID(t)
+	database := openTestDatabase(t, sessionID)
+	ctx := context.Background()
+
+	repository := NewPostgresVoteRepository(database, RepositoryOptions{Outbox: true})
+	results := NewPostgresOutboxResultPublisher(database)
+
+	processed := entity.NewVoteFromData(fmt.Sprintf("%s-processed", sessionID), 1, sessionID, time.Now().UTC(), entity.VoteStatusProcessing)
+	failed := newFailedTestVote(fmt.Sprintf("%s-failed", sessionID), sessionID)
+
+	if _, err := repository.BulkSave(ctx, []*entity.Vote{processed}); err != nil {
+		t.Fatalf("BulkSave() error = %v", err)
+	}
+	processed.MarkAsProcessed()
+
+	// the processed vote's event was written with it and is not repeated
+	if err := results.PublishResults(ctx, []*entity.Vote{processed, failed}); err != nil {
+		t.Fatalf("PublishResults() error = %v", err)
+	}
+
+	publisher := &recordingOutboxPublisher{}
+	if _, err := newTestOutboxRelay(database, publisher).drain(ctx); err != nil {
+		t.Fatalf("drain() error = %v", err)
+	}
+
+	var events []*models.VoteOutboxModel
+	for _, event := range publisher.events {
+		if event.SessionID == sessionID {
+			events = append(events, event)
+		}
+	}
+	if len(events) != 2 {
+		t.Fatalf("published %d events, want 2", len(events))
+	}
+	if events[0].EventType != messagingmodels.EventTypeVoteProcessed || events[0].AggregateID != processed.ID {
+		t.Errorf("first event = %s for %s, want VoteProcessed for %s", events[0].EventType, events[0].AggregateID, processed.ID)
+	}
+	if events[1].EventType != messagingmodels.EventTypeVoteFailed || events[1].AggregateID != failed.ID {
+		t.Errorf("second event = %s for %s, want VoteFailed for %s", events[1].EventType, events[1].AggregateID, failed.ID)
+	}
+
+	var event messagingmodels.VoteResultEvent
+	if err := json.Unmarshal(events[0].Payload, &event); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	var updatedAt time.Time
+	if err := database.DB.QueryRowContext(ctx, "SELECT updated_at FROM votes WHERE id = $1", processed.ID).Scan(&updatedAt); err != nil {
+		t.Fatalf("read updated_at error = %v", err)
+	}
+	// the column keeps microseconds
+	if event.ProcessedAt == nil || event.ProcessedAt.Sub(updatedAt).Abs() > time.Microsecond {
+		t.Errorf("ProcessedAt = %v, want the row's updated_at %v", event.ProcessedAt, updatedAt)
+	}
+}
@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// PostgresOutboxResultPublisher sends result events through vote_outbox, so
+// the relay publishes them in the same order as the VoteProcessed events the
+// repository writes with each vote. Processed votes are skipped here: their
+// event was committed with the vote. Failed votes were never saved, so their
+// VoteFailed event is written on its own.
+type PostgresOutboxResultPublisher struct {
+	db *sql.DB
+}
+
+func NewPostgresOutboxResultPublisher(database *Database) port.VoteResultPublisherPort {
+	return &PostgresOutboxResultPublisher{db: database.DB}
+}
+
+func (p *PostgresOutboxResultPublisher) PublishResults(ctx context.Context, votes []*entity.Vote) error {
+	events := make([]*models.VoteOutboxModel, 0, len(votes))
+
+	for _, vote := range votes {
+		event, err := models.NewFailedVoteOutboxModel(vote)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+
+	return insertOutboxEvents(ctx, p.db, events)
+}
+
+func (p *PostgresOutboxResultPublisher) Close() error {
+	return nil
+}
+
+func insertOutboxEvents(ctx context.Context, exec execer, events []*models.VoteOutboxModel) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*5)
+
+	for i, event := range events {
+		n := i * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, event.AggregateID, event.SessionID, event.EventType, event.Payload, event.CreatedAt)
+	}
+
+	query := `
+		INSERT INTO vote_outbox (
+			aggregate_id, session_id, event_type, payload, created_at
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	if _, err := exec.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to write outbox events: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/util"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
+)
+
+// PostgresInboxConsumer claims rows from vote_inbox with FOR UPDATE SKIP
+// LOCKED, so any number of workers can share the table. A claimed row is
+// leased until leased_until and the lease is renewed while the row is in
+// flight; if the worker dies the lease expires and the row becomes
+// claimable again.
+type PostgresInboxConsumer struct {
+	db       *sql.DB
+	owner    string
+	batch    int
+	poll     time.Duration
+	lease    time.Duration
+	maxTries int
+
+	acksMu sync.Mutex
+	acks   []int64
+
+	// rows claimed and not yet deleted, released or buried
+	inflightMu sync.Mutex
+	inflight   map[int64]struct{}
+
+	wg      sync.WaitGroup
+	renewWg sync.WaitGroup
+	stop    chan struct{}
+}
+
+type inboxVoteDelivery struct {
+	consumer *PostgresInboxConsumer
+	vote     *entity.Vote
+	rowID    int64
+	attempts int
+}
+
+func (d *inboxVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *inboxVoteDelivery) Ack() {
+	d.consumer.acksMu.Lock()
+	d.consumer.acks = append(d.consumer.acks, d.rowID)
+	d.consumer.acksMu.Unlock()
+}
+
+func (d *inboxVoteDelivery) Nack(err error) {
+	d.consumer.release(d.rowID, d.attempts, err)
+}
+
+func (d *inboxVoteDelivery) Reject(err error) {
+	d.consumer.bury(d.rowID, err)
+}
+
+func NewPostgresInboxConsumer(database *Database, cfg *config.ConsumerConfig, batchSize int) port.VoteConsumerPort {
+	return &PostgresInboxConsumer{
+		db:       database.DB,
+		batch:    batchSize,
+		poll:     cfg.InboxPollInterval,
+		lease:    cfg.InboxLeaseDuration,
+		maxTries: cfg.InboxMaxAttempts,
+		inflight: make(map[int64]struct{}),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (c *PostgresInboxConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	owner, err := util.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate inbox consumer id: %w", err)
+	}
+	c.owner = owner
+
+	deliveries := make(chan port.VoteDelivery, c.batch)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(deliveries)
+		c.pollLoop(ctx, deliveries)
+	}()
+
+	// buffered votes are still flushed after ctx is cancelled, so renewal
+	// runs until Close rather than until ctx is done
+	c.renewWg.Add(1)
+	go func() {
+		defer c.renewWg.Done()
+		c.renewLoop()
+	}()
+
+	log.Printf("Inbox consumer %s polling vote_inbox every %s", c.owner, c.poll)
+	return deliveries, nil
+}
+
+func (c *PostgresInboxConsumer) pollLoop(ctx context.Context, deliveries chan<- port.VoteDelivery) {
+	for {
+		c.flushAcks(ctx)
+
+		rows, err := c.claim(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to claim inbox votes: %v", err)
+		}
+
+		for _, row := range rows {
+			delivery := &inboxVoteDelivery{
+				consumer: c,
+				vote:     row.ToEntity(),
+				rowID:    row.ID,
+				attempts: row.Attempts,
+			}
+
+			select {
+			case deliveries <- delivery:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if len(rows) == c.batch {
+			continue
+		}
+
+		select {
+		case <-time.After(c.poll):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *PostgresInboxConsumer) claim(ctx context.Context) ([]*models.VoteInboxModel, error) {
+	query := `
+		UPDATE vote_inbox SET
+			status = $1,
+			leased_by = $2,
+			leased_until = NOW() + ($3 * INTERVAL '1 millisecond'),
+			attempts = attempts + 1,
+			updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM vote_inbox
+			WHERE status = $4
+			   OR (status = $1 AND leased_until < NOW())
+			ORDER BY id
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, vote_id, participant_id, session_id, timestamp, attempts
+	`
+
+	rows, err := c.db.QueryContext(ctx, query,
+		models.InboxStatusLeased,
+		c.owner,
+		c.lease.Milliseconds(),
+		models.InboxStatusPending,
+		c.batch,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease inbox rows: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []*models.VoteInboxModel
+	for rows.Next() {
+		model := &models.VoteInboxModel{}
+		if err := rows.Scan(
+			&model.ID,
+			&model.VoteID,
+			&model.ParticipantID,
+			&model.SessionID,
+			&model.Timestamp,
+			&model.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan inbox row: %w", err)
+		}
+		claimed = append(claimed, model)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inbox rows: %w", err)
+	}
+
+	c.inflightMu.Lock()
+	for _, model := range claimed {
+		c.inflight[model.ID] = struct{}{}
+	}
+	c.inflightMu.Unlock()
+
+	return claimed, nil
+}
+
+// renewLoop extends the lease of in-flight rows every third of the lease
+// duration, so a batch that runs long is not claimed and processed again
+// by another worker.
+func (c *PostgresInboxConsumer) renewLoop() {
+	ticker := time.NewTicker(c.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.renew()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *PostgresInboxConsumer) renew() {
+	c.inflightMu.Lock()
+	ids := make([]int64, 0, len(c.inflight))
+	for id := range c.inflight {
+		ids = append(ids, id)
+	}
+	c.inflightMu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.lease/3)
+	defer cancel()
+
+	_, err := c.db.ExecContext(ctx, `
+		UPDATE vote_inbox SET
+			leased_until = NOW() + ($1 * INTERVAL '1 millisecond'),
+			updated_at = NOW()
+		WHERE id = ANY($2) AND leased_by = $3 AND status = $4
+	`, c.lease.Milliseconds(), pq.Array(ids), c.owner, models.InboxStatusLeased)
+	if err != nil {
+		log.Printf("Failed to renew leases of %d inbox rows: %v", len(ids), err)
+	}
+}
+
+func (c *PostgresInboxConsumer) settled(ids ...int64) {
+	c.inflightMu.Lock()
+	for _, id := range ids {
+		delete(c.inflight, id)
+	}
+	c.inflightMu.Unlock()
+}
+
+// flushAcks deletes acked rows in one statement. Rows whose lease was taken
+// over by another worker are left alone; that worker will settle them.
+func (c *PostgresInboxConsumer) flushAcks(ctx context.Context) {
+	c.acksMu.Lock()
+	acks := c.acks
+	c.acks = nil
+	c.acksMu.Unlock()
+
+	if len(acks) == 0 {
+		return
+	}
+
+	_, err := c.db.ExecContext(ctx,
+		"DELETE FROM vote_inbox WHERE id = ANY($1) AND leased_by = $2",
+		pq.Array(acks),
+		c.owner,
+	)
+	if err != nil {
+		log.Printf("Failed to delete acked inbox rows: %v", err)
+		c.acksMu.Lock()
+		c.acks = append(c.acks, acks...)
+		c.acksMu.Unlock()
+		return
+	}
+
+	c.settled(acks...)
+}
+
+// release and bury stop renewing the row even when the update fails, so its
+// lease expires and it is retried.
+func (c *PostgresInboxConsumer) release(rowID int64, attempts int, reason error) {
+	if attempts >= c.maxTries {
+		c.bury(rowID, fmt.Errorf("max attempts reached: %w", reason))
+		return
+	}
+	defer c.settled(rowID)
+
+	_, err := c.db.Exec(`
+		UPDATE vote_inbox SET
+			status = $1,
+			leased_by = NULL,
+			leased_until = NULL,
+			last_error = $2,
+			updated_at = NOW()
+		WHERE id = $3 AND leased_by = $4
+	`, models.InboxStatusPending, reason.Error(), rowID, c.owner)
+	if err != nil {
+		log.Printf("Failed to release inbox row %d, it will be retried after its lease expires: %v", rowID, err)
+	}
+}
+
+func (c *PostgresInboxConsumer) bury(rowID int64, reason error) {
+	defer c.settled(rowID)
+
+	_, err := c.db.Exec(`
+		UPDATE vote_inbox SET
+			status = $1,
+			leased_by = NULL,
+			leased_until = NULL,
+			last_error = $2,
+			updated_at = NOW()
+		WHERE id = $3 AND leased_by = $4
+	`, models.InboxStatusDead, reason.Error(), rowID, c.owner)
+	if err != nil {
+		log.Printf("Failed to mark inbox row %d as dead: %v", rowID, err)
+	}
+}
+
+func (c *PostgresInboxConsumer) Close() error {
+	c.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.flushAcks(ctx)
+
+	close(c.stop)
+	c.renewWg.Wait()
+
+	return nil
+}
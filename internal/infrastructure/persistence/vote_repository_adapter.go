@@ -3,26 +3,95 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
 	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
 	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/persistence/models"
 )
 
+const (
+	BulkModeInsert = "insert"
+	BulkModeCopy   = "copy"
+)
+
+type RepositoryOptions struct {
+	// Outbox writes a VoteProcessed event to vote_outbox in the same
+	// transaction as the votes, for OutboxRelay to deliver.
+	Outbox bool
+	// BulkMode selects how BulkSave writes: a multi-row INSERT, or COPY
+	// into a staging table merged into votes.
+	BulkMode string
+	// ChunkSize caps how many votes BulkSave writes per statement. It is
+	// clamped to maxChunkSize, and zero means maxChunkSize.
+	ChunkSize int
+	// ChunkTransactions commits each chunk in its own transaction instead
+	// of the whole batch in one; failed chunks are reported in a
+	// port.BulkSaveError.
+	ChunkTransactions bool
+	// InsertOnly never overwrites a stored vote: votes whose ID is already
+	// stored are skipped and reported as duplicates, and get no outbox
+	// event.
+	InsertOnly bool
+}
+
+// maxBulkParams is the protocol's limit on bind parameters per statement.
+const maxBulkParams = 65535
+
+// maxChunkSize is the most rows the multi-row vote INSERT, the widest
+// statement a chunk produces, can carry under maxBulkParams.
+var maxChunkSize = maxBulkParams / len(voteColumns)
+
+var voteColumns = []string{
+	"id", "participant_id", "session_id", "timestamp", "status",
+	"processed_at", "processing_error", "created_at", "updated_at",
+}
+
+// stagingColumns are the columns copied into votes_staging: the vote plus
+// its position in the batch.
+var stagingColumns = append(append([]string{}, voteColumns...), "ordinal")
+
+const voteUpsertClause = `
+		ON CONFLICT (id) DO UPDATE SET
+			participant_id = EXCLUDED.participant_id,
+			session_id = EXCLUDED.session_id,
+			timestamp = EXCLUDED.timestamp,
+			status = EXCLUDED.status,
+			processed_at = EXCLUDED.processed_at,
+			processing_error = EXCLUDED.processing_error,
+			updated_at = EXCLUDED.updated_at
+	`
+
+const voteInsertOnlyClause = `
+		ON CONFLICT (id) DO NOTHING
+		RETURNING id
+	`
+
 type PostgresVoteRepository struct {
 	db     *sql.DB
+	opts   RepositoryOptions
 }
 
-func NewPostgresVoteRepository(database *Database) port.VoteRepositoryPort {
+func NewPostgresVoteRepository(database *Database, opts RepositoryOptions) port.VoteRepositoryPort {
 	return &PostgresVoteRepository{
 		db:     database.DB,
+		opts:   opts,
 	}
 }
 
 func (r *PostgresVoteRepository) Save(ctx context.Context, vote *entity.Vote) error {
+	return classifyError(r.save(ctx, vote))
+}
+
+func (r *PostgresVoteRepository) save(ctx context.Context, vote *entity.Vote) error {
 	if vote == nil {
 		return fmt.Errorf("vote cannot be nil")
 	}
@@ -40,17 +109,15 @@ func (r *PostgresVoteRepository) Save(ctx context.Context, vote *entity.Vote) er
 			processed_at, processing_error, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9
-		) ON CONFLICT (id) DO UPDATE SET
-			participant_id = EXCLUDED.participant_id,
-			session_id = EXCLUDED.session_id,
-			timestamp = EXCLUDED.timestamp,
-			status = EXCLUDED.status,
-			processed_at = EXCLUDED.processed_at,
-			processing_error = EXCLUDED.processing_error,
-			updated_at = EXCLUDED.updated_at
-	`
+		)` + r.conflictClause()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		model.ID,
 		model.ParticipantID,
 		model.SessionID,
@@ -66,51 +133,296 @@ func (r *PostgresVoteRepository) Save(ctx context.Context, vote *entity.Vote) er
 		return fmt.Errorf("failed to save vote: %w", err)
 	}
 
+	if r.opts.InsertOnly {
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to save vote: %w", err)
+		}
+		if inserted == 0 {
+			return port.ErrDuplicateVote
+		}
+	}
+
+	if err := r.writeOutbox(ctx, tx, []*entity.Vote{vote}, model.UpdatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-func (r *PostgresVoteRepository) BulkSave(ctx context.Context, votes []*entity.Vote) error {
+func (r *PostgresVoteRepository) BulkSave(ctx context.Context, votes []*entity.Vote) ([]string, error) {
 	if len(votes) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	for i, vote := range votes {
 		if vote == nil {
-			return fmt.Errorf("vote at index %d cannot be nil", i)
+			return nil, fmt.Errorf("vote at index %d cannot be nil", i)
 		}
 		if err := vote.Validate(); err != nil {
-			return fmt.Errorf("invalid vote at index %d: %w", i, err)
+			return nil, &port.RowError{Err: fmt.Errorf("invalid vote at index %d: %w", i, err)}
 		}
 	}
 
-	models := r.convertToModels(votes)
+	chunks := r.chunk(len(votes))
+
+	if r.opts.ChunkTransactions {
+		return r.saveChunks(ctx, votes, chunks)
+	}
+
+	duplicates, err := r.saveAll(ctx, votes, chunks)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	logChunks(chunks)
+	return duplicates, nil
+}
 
+func (r *PostgresVoteRepository) saveAll(ctx context.Context, votes []*entity.Vote, chunks []port.ChunkResult) ([]string, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	query, args := r.buildBulkInsertQuery(models)
+	var duplicates []string
+	for i := range chunks {
+		chunk := &chunks[i]
+		started := time.Now()
+		chunkDuplicates, err := r.writeChunk(ctx, tx, votes[chunk.Offset:chunk.Offset+chunk.Size])
+		if err != nil {
+			return nil, err
+		}
+		chunk.Duration = time.Since(started)
+		duplicates = append(duplicates, chunkDuplicates...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// saveChunks commits every chunk in its own transaction, carrying on past
+// failed chunks so one bad chunk does not hold back the rest.
+func (r *PostgresVoteRepository) saveChunks(ctx context.Context, votes []*entity.Vote, chunks []port.ChunkResult) ([]string, error) {
+	var duplicates []string
+	failed := false
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		started := time.Now()
+		chunkDuplicates, err := r.saveChunk(ctx, votes[chunk.Offset:chunk.Offset+chunk.Size])
+		chunk.Duration = time.Since(started)
+		chunk.Err = classifyError(err)
+
+		if chunk.Err != nil {
+			failed = true
+			continue
+		}
+		duplicates = append(duplicates, chunkDuplicates...)
+	}
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	logChunks(chunks)
+
+	if failed {
+		return duplicates, &port.BulkSaveError{Chunks: chunks}
+	}
+	return duplicates, nil
+}
+
+func (r *PostgresVoteRepository) saveChunk(ctx context.Context, votes []*entity.Vote) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to bulk save votes: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	duplicates, err := r.writeChunk(ctx, tx, votes)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return duplicates, nil
 }
 
-func (r *PostgresVoteRepository) convertToModels(votes []*entity.Vote) []*models.VoteModel {
+func (r *PostgresVoteRepository) writeChunk(ctx context.Context, tx *sql.Tx, votes []*entity.Vote) ([]string, error) {
+	writtenAt := time.Now().UTC()
+	models := r.convertToModels(votes, writtenAt)
+
+	var inserted map[string]bool
+	var err error
+	if r.opts.BulkMode == BulkModeCopy {
+		inserted, err = r.copyVotes(ctx, tx, models)
+	} else {
+		query, args := r.buildBulkInsertQuery(models)
+		inserted, err = r.insertVotes(ctx, tx, query, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk save votes: %w", err)
+	}
+
+	if !r.opts.InsertOnly {
+		return nil, r.writeOutbox(ctx, tx, votes, writtenAt)
+	}
+
+	fresh, duplicates := splitDuplicates(votes, inserted)
+	return duplicates, r.writeOutbox(ctx, tx, fresh, writtenAt)
+}
+
+// insertVotes runs an INSERT into votes. In insert-only mode it returns the
+// IDs the statement's RETURNING clause reported as inserted.
+func (r *PostgresVoteRepository) insertVotes(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (map[string]bool, error) {
+	if !r.opts.InsertOnly {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inserted := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		inserted[id] = true
+	}
+
+	return inserted, rows.Err()
+}
+
+// splitDuplicates separates the votes that were inserted from those whose
+// ID was already stored. Of several votes sharing an inserted ID, only the
+// first counts as inserted.
+func splitDuplicates(votes []*entity.Vote, inserted map[string]bool) ([]*entity.Vote, []string) {
+	fresh := make([]*entity.Vote, 0, len(votes))
+	var duplicates []string
+
+	for _, vote := range votes {
+		if inserted[vote.ID] {
+			delete(inserted, vote.ID)
+			fresh = append(fresh, vote)
+			continue
+		}
+		duplicates = append(duplicates, vote.ID)
+	}
+
+	return fresh, duplicates
+}
+
+func (r *PostgresVoteRepository) conflictClause() string {
+	if r.opts.InsertOnly {
+		return voteInsertOnlyClause
+	}
+	return voteUpsertClause
+}
+
+// chunk splits n votes into consecutive ranges of at most the chunk size.
+func (r *PostgresVoteRepository) chunk(n int) []port.ChunkResult {
+	size := r.opts.ChunkSize
+	if size <= 0 || size > maxChunkSize {
+		size = maxChunkSize
+	}
+
+	chunks := make([]port.ChunkResult, 0, (n+size-1)/size)
+	for offset := 0; offset < n; offset += size {
+		chunks = append(chunks, port.ChunkResult{Offset: offset, Size: min(size, n-offset)})
+	}
+
+	return chunks
+}
+
+// classifyError marks errors worth retrying as they are: lost connections,
+// serialization failures and deadlocks, and the server running out of
+// resources or shutting down. Cardinality violations, such as one statement
+// updating a row twice, data exceptions and integrity violations are row
+// errors, caused by the votes themselves; anything else, like a missing
+// table or a permission error, is left as is.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "40", "53", "57":
+			return &port.TransientError{Err: err}
+		case "21", "22", "23":
+			return &port.RowError{Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return &port.TransientError{Err: err}
+	}
+
+	return err
+}
+
+func logChunks(chunks []port.ChunkResult) {
+	if len(chunks) < 2 {
+		return
+	}
+
+	for i, chunk := range chunks {
+		if chunk.Err != nil {
+			log.Printf("Bulk save chunk %d/%d failed: %d votes in %s: %v", i+1, len(chunks), chunk.Size, chunk.Duration, chunk.Err)
+			continue
+		}
+		log.Printf("Bulk save chunk %d/%d: %d votes in %s", i+1, len(chunks), chunk.Size, chunk.Duration)
+	}
+}
+
+// writeOutbox records a VoteProcessed event per vote, processed at
+// writtenAt, the time the vote rows carry as updated_at.
+func (r *PostgresVoteRepository) writeOutbox(ctx context.Context, tx *sql.Tx, votes []*entity.Vote, writtenAt time.Time) error {
+	if !r.opts.Outbox || len(votes) == 0 {
+		return nil
+	}
+
+	events := make([]*models.VoteOutboxModel, len(votes))
+	for i, vote := range votes {
+		event, err := models.NewProcessedVoteOutboxModel(vote, writtenAt)
+		if err != nil {
+			return err
+		}
+		events[i] = event
+	}
+
+	return insertOutboxEvents(ctx, tx, events)
+}
+
+// convertToModels stamps every model with the same writtenAt, so all rows
+// of a chunk and their outbox events agree on when they were written.
+func (r *PostgresVoteRepository) convertToModels(votes []*entity.Vote, writtenAt time.Time) []*models.VoteModel {
 	modelsList := make([]*models.VoteModel, len(votes))
 
 	for i, vote := range votes {
 		model := &models.VoteModel{}
 		model.FromEntity(vote)
+		model.CreatedAt = writtenAt
+		model.UpdatedAt = writtenAt
 		modelsList[i] = model
 	}
 
@@ -132,9 +444,16 @@ func (r *PostgresVoteRepository) buildBulkInsertQuery(models []*models.VoteModel
 	var args []interface{}
 	argIndex := 1
 
-	now := time.Now().UTC()
+	// only the first vote per id is written, as copyVotes does, since ON
+	// CONFLICT cannot update the same row twice in one statement
+	seen := make(map[string]bool, len(models))
 
 	for _, model := range models {
+		if seen[model.ID] {
+			continue
+		}
+		seen[model.ID] = true
+
 		placeholder := fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
 			argIndex, argIndex+1, argIndex+2, argIndex+3, argIndex+4,
 			argIndex+5, argIndex+6, argIndex+7, argIndex+8)
@@ -149,8 +468,8 @@ func (r *PostgresVoteRepository) buildBulkInsertQuery(models []*models.VoteModel
 			model.Status,
 			model.ProcessedAt,
 			model.ProcessingError,
-			now,
-			now,
+			model.CreatedAt,
+			model.UpdatedAt,
 		)
 
 		argIndex += 9
@@ -158,16 +477,74 @@ func (r *PostgresVoteRepository) buildBulkInsertQuery(models []*models.VoteModel
 
 	query += strings.Join(placeholders, ", ")
 
-	query += `
-		ON CONFLICT (id) DO UPDATE SET
-			participant_id = EXCLUDED.participant_id,
-			session_id = EXCLUDED.session_id,
-			timestamp = EXCLUDED.timestamp,
-			status = EXCLUDED.status,
-			processed_at = EXCLUDED.processed_at,
-			processing_error = EXCLUDED.processing_error,
-			updated_at = EXCLUDED.updated_at
-	`
+	query += r.conflictClause()
 
 	return query, args
 }
+
+// copyVotes streams the batch with COPY into a temporary staging table and
+// merges it into votes with one statement. Each staged row keeps its
+// position in the batch, so the first of several votes sharing an ID is the
+// one merged. The staging table is dropped afterwards so the next chunk in
+// the same transaction can create it again.
+func (r *PostgresVoteRepository) copyVotes(ctx context.Context, tx *sql.Tx, votes []*models.VoteModel) (map[string]bool, error) {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE votes_staging (
+			LIKE votes INCLUDING DEFAULTS,
+			ordinal INTEGER NOT NULL
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("votes_staging", stagingColumns...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, model := range votes {
+		_, err := stmt.ExecContext(ctx,
+			model.ID,
+			model.ParticipantID,
+			model.SessionID,
+			model.Timestamp,
+			model.Status,
+			model.ProcessedAt,
+			model.ProcessingError,
+			model.CreatedAt,
+			model.UpdatedAt,
+			i,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy vote %s: %w", model.ID, err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish copy: %w", err)
+	}
+
+	columns := strings.Join(voteColumns, ", ")
+
+	// DISTINCT ON keeps the first row per id, since ON CONFLICT cannot
+	// update the same row twice in one statement
+	inserted, err := r.insertVotes(ctx, tx, `
+		INSERT INTO votes (`+columns+`)
+		SELECT DISTINCT ON (id) `+columns+`
+		FROM votes_staging
+		ORDER BY id, ordinal`+r.conflictClause())
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge staged votes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE votes_staging"); err != nil {
+		return nil, fmt.Errorf("failed to drop staging table: %w", err)
+	}
+
+	return inserted, nil
+}
@@ -0,0 +1,201 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/config"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+)
+
+const benchSessionID = "bench-bulk-save"
+
+// openTestDatabase connects with the usual DB_* variables and skips the
+// test when no Postgres is reachable. Votes and outbox events of sessionID
+// are removed afterwards.
+func openTestDatabase(tb testing.TB, sessionID string) *Database {
+	tb.Helper()
+
+	cfg := config.Load().Database
+	cfg.MigrationsPath = "migrations"
+
+	database, err := NewConnection(&cfg)
+	if err != nil {
+		tb.Skipf("postgres not available: %v", err)
+	}
+
+	if err := NewMigrator(database.DB, cfg.MigrationsPath).Run(); err != nil {
+		database.Close()
+		tb.Fatalf("failed to run migrations: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		database.DB.Exec("DELETE FROM votes WHERE session_id = $1", sessionID)
+		database.DB.Exec("DELETE FROM vote_outbox WHERE session_id = $1", sessionID)
+		database.Close()
+	})
+
+	return database
+}
+
+// IDs are unique per call, so every batch inserts rather than updates
+func newBenchVotes(size int) []*entity.Vote {
+	prefix := fmt.Sprintf("bench-%d", time.Now().UnixNano())
+
+	votes := make([]*entity.Vote, size)
+	for i := range votes {
+		votes[i] = entity.NewVoteFromData(
+			fmt.Sprintf("%s-%d", prefix, i),
+			i%500+1,
+			benchSessionID,
+			time.Now().UTC(),
+			entity.VoteStatusProcessing,
+		)
+	}
+	return votes
+}
+
+func BenchmarkBulkSave(b *testing.B) {
+	database := openTestDatabase(b, benchSessionID)
+	ctx := context.Background()
+
+	for _, mode := range []string{BulkModeInsert, BulkModeCopy} {
+		repository := NewPostgresVoteRepository(database, RepositoryOptions{BulkMode: mode})
+
+		for _, size := range []int{100, 1000, 5000, 20000} {
+			b.Run(fmt.Sprintf("%s/%d", mode, size), func(b *testing.B) {
+				batches := make([][]*entity.Vote, b.N)
+				for i := range batches {
+					batches[i] = newBenchVotes(size)
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := repository.BulkSave(ctx, batches[i]); err != nil {
+						b.Fatalf("BulkSave() error = %v", err)
+					}
+				}
+				b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "votes/s")
+			})
+		}
+	}
+}
+
+func TestChunkCoversBatchUnderParameterLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		votes     int
+		want      []port.ChunkResult
+	}{
+		{"single chunk", 100, 40, []port.ChunkResult{{Offset: 0, Size: 40}}},
+		{"remainder", 100, 250, []port.ChunkResult{{Offset: 0, Size: 100}, {Offset: 100, Size: 100}, {Offset: 200, Size: 50}}},
+		{"default", 0, maxChunkSize + 1, []port.ChunkResult{{Offset: 0, Size: maxChunkSize}, {Offset: maxChunkSize, Size: 1}}},
+		{"clamped", 50000, maxChunkSize * 2, []port.ChunkResult{{Offset: 0, Size: maxChunkSize}, {Offset: maxChunkSize, Size: maxChunkSize}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &PostgresVoteRepository{opts: RepositoryOptions{ChunkSize: tt.chunkSize}}
+
+			got := repository.chunk(tt.votes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunk(%d) = %+v, want %+v", tt.votes, got, tt.want)
+			}
+			for _, chunk := range got {
+				if params := chunk.Size * len(voteColumns); params > maxBulkParams {
+					t.Errorf("chunk of %d votes needs %d parameters", chunk.Size, params)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyErrorMarksRetryableFailuresTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, true},
+		{"bad connection", fmt.Errorf("failed to begin transaction: %w", driver.ErrBadConn), true},
+		{"foreign key violation", &pq.Error{Code: "23503"}, false},
+		{"invalid text", &pq.Error{Code: "22P02"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(fmt.Errorf("failed to bulk save votes: %w", tt.err))
+			if got := port.IsTransient(err); got != tt.transient {
+				t.Errorf("IsTransient() = %t, want %t", got, tt.transient)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorMarksRefusedRowsRowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		row  bool
+	}{
+		{"cardinality violation", &pq.Error{Code: "21000"}, true},
+		{"invalid text", &pq.Error{Code: "22P02"}, true},
+		{"unique violation", &pq.Error{Code: "23505"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, false},
+		{"undefined table", &pq.Error{Code: "42P01"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(fmt.Errorf("failed to bulk save votes: %w", tt.err))
+			if got := port.IsRowError(err); got != tt.row {
+				t.Errorf("IsRowError() = %t, want %t", got, tt.row)
+			}
+		})
+	}
+}
+
+func TestBuildBulkInsertQueryKeepsFirstVotePerID(t *testing.T) {
+	votes := newBenchVotes(3)
+	votes[2].ID = votes[0].ID
+	votes[2].ParticipantID = 99
+
+	repository := &PostgresVoteRepository{}
+	query, args := repository.buildBulkInsertQuery(repository.convertToModels(votes, time.Now().UTC()))
+
+	if got := len(args); got != 2*len(voteColumns) {
+		t.Fatalf("args = %d, want %d for 2 votes", got, 2*len(voteColumns))
+	}
+	if strings.Contains(query, "$19") {
+		t.Errorf("query has a placeholder for the repeated vote: %s", query)
+	}
+	if args[0] != votes[0].ID || args[1] != votes[0].ParticipantID || args[len(voteColumns)] != votes[1].ID {
+		t.Errorf("args = %v, want votes 0 and 1", args)
+	}
+}
+
+func TestSplitDuplicatesKeepsFirstVoteOfInsertedID(t *testing.T) {
+	votes := newBenchVotes(4)
+	votes[3].ID = votes[0].ID
+
+	fresh, duplicates := splitDuplicates(votes, map[string]bool{votes[0].ID: true, votes[2].ID: true})
+
+	if len(fresh) != 2 || fresh[0] != votes[0] || fresh[1] != votes[2] {
+		t.Errorf("fresh = %v, want votes 0 and 2", fresh)
+	}
+	if want := []string{votes[1].ID, votes[0].ID}; !reflect.DeepEqual(duplicates, want) {
+		t.Errorf("duplicates = %v, want %v", duplicates, want)
+	}
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type Checkpoint struct {
+	File      string    `json:"file"`
+	Offset    int64     `json:"offset"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(content, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", path, err)
+	}
+
+	return checkpoint, nil
+}
+
+func (c *Checkpoint) Save(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint %s: %w", path, err)
+	}
+
+	return nil
+}
+
+type windowEntry struct {
+	end   int64
+	acked bool
+}
+
+// offsetWindow tracks lines in read order so the checkpoint only moves past
+// a line once it and every line before it were settled.
+type offsetWindow struct {
+	mu        sync.Mutex
+	base      int64
+	pending   []windowEntry
+	committed int64
+}
+
+func newOffsetWindow(start int64) *offsetWindow {
+	return &offsetWindow{
+		committed: start,
+	}
+}
+
+func (w *offsetWindow) add(end int64) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, windowEntry{end: end})
+	return w.base + int64(len(w.pending)) - 1
+}
+
+func (w *offsetWindow) ack(seq int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	index := seq - w.base
+	if index < 0 || index >= int64(len(w.pending)) {
+		return
+	}
+	w.pending[index].acked = true
+
+	for len(w.pending) > 0 && w.pending[0].acked {
+		w.committed = w.pending[0].end
+		w.pending = w.pending[1:]
+		w.base++
+	}
+}
+
+func (w *offsetWindow) offset() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.committed
+}
@@ -0,0 +1,186 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging/models"
+)
+
+type Format string
+
+const (
+	FormatAuto   Format = "auto"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "", FormatAuto:
+		return FormatAuto, nil
+	case FormatNDJSON, FormatCSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown replay format: %s", value)
+}
+
+func detectFormat(path string) Format {
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	if filepath.Ext(name) == ".csv" {
+		return FormatCSV
+	}
+	return FormatNDJSON
+}
+
+// record is one entry of a replay file: either a parsed vote message or the
+// error that kept it from parsing. A blank NDJSON line carries neither.
+type record struct {
+	message *models.VoteMessage
+	err     error
+	line    int64
+	end     int64
+}
+
+func (r *record) blank() bool {
+	return r.message == nil && r.err == nil
+}
+
+type recordReader interface {
+	// Read returns the next record, or io.EOF once the stream is exhausted.
+	// Any other error means the stream itself could not be read.
+	Read() (*record, error)
+	// Offset is the byte position just past the last record read.
+	Offset() int64
+}
+
+type ndjsonReader struct {
+	reader   *bufio.Reader
+	decoders *messaging.DecoderRegistry
+	position int64
+	line     int64
+}
+
+func newNDJSONReader(source io.Reader, decoders *messaging.DecoderRegistry) *ndjsonReader {
+	return &ndjsonReader{
+		reader:   bufio.NewReader(source),
+		decoders: decoders,
+	}
+}
+
+func (r *ndjsonReader) Read() (*record, error) {
+	data, err := r.reader.ReadBytes('\n')
+	if len(data) == 0 {
+		return nil, err
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	r.position += int64(len(data))
+	r.line++
+
+	rec := &record{line: r.line, end: r.position}
+	if data = bytes.TrimSpace(data); len(data) > 0 {
+		rec.message, rec.err = r.decoders.Decode(data, nil)
+	}
+	return rec, nil
+}
+
+func (r *ndjsonReader) Offset() int64 {
+	return r.position
+}
+
+// csvReader maps columns by the header row, so only the columns named
+// id, participanteId, sessionId and timestamp are read, in any order. It
+// parses the stream directly, so quoted fields may span several lines.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(source io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1
+
+	fields, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(fields))
+	for i, field := range fields {
+		columns[strings.ToLower(strings.TrimSpace(field))] = i
+	}
+
+	for _, required := range []string{"participanteid", "sessionid", "timestamp"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %s", required)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (*record, error) {
+	fields, err := r.reader.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &record{err: err, line: int64(parseErr.StartLine), end: r.Offset()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	rec := &record{line: int64(line), end: r.Offset()}
+	rec.message, rec.err = r.parse(fields)
+	return rec, nil
+}
+
+func (r *csvReader) Offset() int64 {
+	return r.reader.InputOffset()
+}
+
+func (r *csvReader) parse(fields []string) (*models.VoteMessage, error) {
+	value := func(column string) string {
+		index, ok := r.columns[column]
+		if !ok || index >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[index])
+	}
+
+	message := &models.VoteMessage{
+		ID:        value("id"),
+		SessionID: value("sessionid"),
+	}
+
+	if raw := value("participanteid"); raw != "" {
+		participantID, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid participanteId %q: %w", raw, err)
+		}
+		message.ParticipanteID = participantID
+	}
+
+	if raw := value("timestamp"); raw != "" {
+		timestamp, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", raw, err)
+		}
+		message.Timestamp = timestamp
+	}
+
+	return message, nil
+}
@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, reader recordReader) []*record {
+	t.Helper()
+
+	var records []*record
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestCSVReaderKeepsQuotedNewlinesInOneRecord(t *testing.T) {
+	input := "id,participanteId,sessionId,timestamp\n" +
+		"a,1,\"session\nwith newline\",2024-01-01T12:00:00Z\n" +
+		"b,2,session-2,2024-01-01T12:00:01Z\n"
+
+	reader, err := newCSVReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("newCSVReader() error = %v", err)
+	}
+
+	records := readAll(t, reader)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	first, second := records[0], records[1]
+	if first.err != nil || second.err != nil {
+		t.Fatalf("record errors = %v, %v", first.err, second.err)
+	}
+	if first.message.SessionID != "session\nwith newline" {
+		t.Errorf("first SessionID = %q", first.message.SessionID)
+	}
+	if second.message.ID != "b" || second.message.ParticipanteID != 2 {
+		t.Errorf("second record = %+v", second.message)
+	}
+	if first.line != 2 || second.line != 4 {
+		t.Errorf("lines = %d, %d, want 2, 4", first.line, second.line)
+	}
+	if second.end != int64(len(input)) {
+		t.Errorf("second end = %d, want %d", second.end, len(input))
+	}
+}
+
+func TestCSVReaderResumesFromRecordOffset(t *testing.T) {
+	input := "id,participanteId,sessionId,timestamp\n" +
+		"a,1,\"multi\nline\",2024-01-01T12:00:00Z\n" +
+		"b,2,session-2,2024-01-01T12:00:01Z\n"
+
+	reader, err := newCSVReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("newCSVReader() error = %v", err)
+	}
+	checkpoint := readAll(t, reader)[0].end
+
+	resumed, err := newCSVReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("newCSVReader() error = %v", err)
+	}
+	for resumed.Offset() < checkpoint {
+		if _, err := resumed.Read(); err != nil {
+			t.Fatalf("Read() while skipping error = %v", err)
+		}
+	}
+
+	rest := readAll(t, resumed)
+	if len(rest) != 1 || rest[0].message.ID != "b" {
+		t.Fatalf("records after checkpoint = %+v, want only b", rest)
+	}
+}
+
+func TestCSVReaderReportsMalformedRecordAndContinues(t *testing.T) {
+	input := "id,participanteId,sessionId,timestamp\n" +
+		"a,1,bad\"quote,2024-01-01T12:00:00Z\n" +
+		"b,2,session-2,2024-01-01T12:00:01Z\n"
+
+	reader, err := newCSVReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("newCSVReader() error = %v", err)
+	}
+
+	records := readAll(t, reader)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].err == nil {
+		t.Error("malformed record has no error")
+	}
+	if records[1].err != nil || records[1].message.ID != "b" {
+		t.Errorf("record after malformed one = %+v, err = %v", records[1].message, records[1].err)
+	}
+}
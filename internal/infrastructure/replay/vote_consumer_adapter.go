@@ -0,0 +1,328 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/entity"
+	"github.com/pdrhp/ms-voto-processor-go/internal/core/port"
+	"github.com/pdrhp/ms-voto-processor-go/internal/infrastructure/messaging"
+)
+
+type Options struct {
+	Path             string
+	Format           Format
+	CheckpointPath   string
+	StartOffset      int64
+	ProgressInterval time.Duration
+	Buffer           int
+	DryRun           bool
+}
+
+// FileVoteConsumer replays NDJSON or CSV vote files, optionally gzipped.
+// Offsets are byte positions in the decompressed stream; the checkpoint
+// holds the end of the last record settled with every record before it, so
+// a rerun resumes without skipping votes that were never saved.
+type FileVoteConsumer struct {
+	opts     Options
+	decoders *messaging.DecoderRegistry
+
+	window   *offsetWindow
+	progress replayProgress
+
+	wg sync.WaitGroup
+}
+
+type replayProgress struct {
+	lines     atomic.Int64
+	processed atomic.Int64
+	invalid   atomic.Int64
+	rejected  atomic.Int64
+	failed    atomic.Int64
+	readBytes atomic.Int64
+	fileSize  int64
+}
+
+type fileVoteDelivery struct {
+	consumer *FileVoteConsumer
+	vote     *entity.Vote
+	seq      int64
+	line     int64
+}
+
+func (d *fileVoteDelivery) Vote() *entity.Vote {
+	return d.vote
+}
+
+func (d *fileVoteDelivery) Ack() {
+	d.consumer.progress.processed.Add(1)
+	d.consumer.window.ack(d.seq)
+}
+
+// a failed line is left unsettled so the checkpoint stops before it
+func (d *fileVoteDelivery) Nack(err error) {
+	d.consumer.progress.failed.Add(1)
+	log.Printf("Failed to process replayed vote: line=%d ID=%s error=%v", d.line, d.vote.ID, err)
+}
+
+func (d *fileVoteDelivery) Reject(err error) {
+	d.consumer.progress.rejected.Add(1)
+	log.Printf("Replayed vote rejected: line=%d ID=%s error=%v", d.line, d.vote.ID, err)
+	d.consumer.window.ack(d.seq)
+}
+
+func NewFileVoteConsumer(opts Options, decoders *messaging.DecoderRegistry) port.VoteConsumerPort {
+	if opts.Format == "" || opts.Format == FormatAuto {
+		opts.Format = detectFormat(opts.Path)
+	}
+	if opts.CheckpointPath == "" {
+		opts.CheckpointPath = opts.Path + ".checkpoint"
+	}
+
+	return &FileVoteConsumer{
+		opts:     opts,
+		decoders: decoders,
+	}
+}
+
+func (c *FileVoteConsumer) Consume(ctx context.Context) (<-chan port.VoteDelivery, error) {
+	start, err := c.resolveStartOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(c.opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+
+	if info, err := file.Stat(); err == nil {
+		c.progress.fileSize = info.Size()
+	}
+
+	c.window = newOffsetWindow(start)
+	deliveries := make(chan port.VoteDelivery, c.opts.Buffer)
+
+	log.Printf("Replaying %s (%s) from offset %d, dry run: %t", c.opts.Path, c.opts.Format, start, c.opts.DryRun)
+
+	readDone := make(chan struct{})
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		defer close(readDone)
+		defer close(deliveries)
+		defer file.Close()
+
+		if err := c.readLoop(ctx, file, start, deliveries); err != nil {
+			log.Printf("Replay stopped: %v", err)
+		}
+	}()
+	go func() {
+		defer c.wg.Done()
+		c.progressLoop(ctx, readDone)
+	}()
+
+	return deliveries, nil
+}
+
+func (c *FileVoteConsumer) resolveStartOffset() (int64, error) {
+	if c.opts.StartOffset >= 0 {
+		return c.opts.StartOffset, nil
+	}
+
+	checkpoint, err := LoadCheckpoint(c.opts.CheckpointPath)
+	if err != nil {
+		return 0, err
+	}
+	if checkpoint == nil {
+		return 0, nil
+	}
+
+	log.Printf("Resuming replay from checkpoint %s at offset %d", c.opts.CheckpointPath, checkpoint.Offset)
+	return checkpoint.Offset, nil
+}
+
+func (c *FileVoteConsumer) readLoop(ctx context.Context, file *os.File, start int64, deliveries chan<- port.VoteDelivery) error {
+	source, err := c.openStream(file)
+	if err != nil {
+		return err
+	}
+
+	var reader recordReader = newNDJSONReader(source, c.decoders)
+	if c.opts.Format == FormatCSV {
+		if reader, err = newCSVReader(source); err != nil {
+			return err
+		}
+	}
+
+	for reader.Offset() < start {
+		if _, err := reader.Read(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to skip to offset %d: %w", start, err)
+		}
+	}
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read past offset %d: %w", reader.Offset(), err)
+		}
+
+		if rec.blank() {
+			continue
+		}
+
+		c.progress.lines.Add(1)
+		seq := c.window.add(rec.end)
+
+		message, err := rec.message, rec.err
+		if err == nil {
+			err = message.Validate()
+		}
+		if err != nil {
+			c.progress.invalid.Add(1)
+			log.Printf("Skipping invalid line %d: %v", rec.line, err)
+			c.window.ack(seq)
+			continue
+		}
+
+		// byte offsets are stable across reruns, so a replayed line keeps its vote ID
+		message.Origin = fmt.Sprintf("file:%s@%d", filepath.Base(c.opts.Path), rec.end)
+
+		vote, err := message.ToEntity()
+		if err != nil {
+			return fmt.Errorf("failed to build vote from line %d: %w", rec.line, err)
+		}
+
+		delivery := &fileVoteDelivery{
+			consumer: c,
+			vote:     vote,
+			seq:      seq,
+			line:     rec.line,
+		}
+
+		select {
+		case deliveries <- delivery:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *FileVoteConsumer) openStream(file *os.File) (io.Reader, error) {
+	counted := &countingReader{reader: file, count: &c.progress.readBytes}
+	buffered := bufio.NewReader(counted)
+
+	magic, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read replay file: %w", err)
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		stream, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		return stream, nil
+	}
+
+	return buffered, nil
+}
+
+func (c *FileVoteConsumer) progressLoop(ctx context.Context, readDone <-chan struct{}) {
+	if c.opts.ProgressInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.opts.ProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.reportProgress()
+			c.saveCheckpoint()
+		case <-readDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *FileVoteConsumer) reportProgress() {
+	percent := 100.0
+	if c.progress.fileSize > 0 {
+		percent = float64(c.progress.readBytes.Load()) / float64(c.progress.fileSize) * 100
+	}
+
+	log.Printf("Replay progress: %.1f%% read, %d lines, %d processed, %d invalid, %d rejected, %d failed, checkpoint offset %d",
+		percent,
+		c.progress.lines.Load(),
+		c.progress.processed.Load(),
+		c.progress.invalid.Load(),
+		c.progress.rejected.Load(),
+		c.progress.failed.Load(),
+		c.window.offset(),
+	)
+}
+
+func (c *FileVoteConsumer) saveCheckpoint() {
+	if c.opts.DryRun || c.window == nil {
+		return
+	}
+
+	checkpoint := &Checkpoint{
+		File:      c.opts.Path,
+		Offset:    c.window.offset(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := checkpoint.Save(c.opts.CheckpointPath); err != nil {
+		log.Printf("Failed to save replay checkpoint: %v", err)
+	}
+}
+
+// Failed is the number of votes nacked so far; their lines stay ahead of the
+// checkpoint and are replayed by the next run.
+func (c *FileVoteConsumer) Failed() int64 {
+	return c.progress.failed.Load()
+}
+
+func (c *FileVoteConsumer) Close() error {
+	c.wg.Wait()
+
+	if c.window != nil {
+		c.reportProgress()
+		c.saveCheckpoint()
+	}
+
+	return nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}